@@ -117,6 +117,46 @@ func NewFqR() (fields.Fq, error) {
 	return fqR, nil
 }
 
+// inField returns true if all the values are in [0, Q)
+func (bn128 Bn128) inField(vs ...*big.Int) bool {
+	for _, v := range vs {
+		if v == nil || v.Sign() < 0 || v.Cmp(bn128.Q) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsInG2Subgroup returns true if the point is in the subgroup of order R of the twist curve, that is R * p == 0. Unlike G1, the twist curve has other points that are not in this subgroup
+func (bn128 Bn128) IsInG2Subgroup(p [3][2]*big.Int) bool {
+	return bn128.G2.IsZero(bn128.G2.MulScalar(p, bn128.R))
+}
+
+// CheckG1 returns an error if the point is not a valid G1 point: its coordinates must be in the field and it must be on the curve
+func (bn128 Bn128) CheckG1(p [3]*big.Int) error {
+	if !bn128.inField(p[0], p[1], p[2]) {
+		return errors.New("G1 point coordinate out of the field")
+	}
+	if !bn128.G1.IsOnCurve(p) {
+		return errors.New("G1 point not on the curve")
+	}
+	return nil
+}
+
+// CheckG2 returns an error if the point is not a valid G2 point: its coordinates must be in the field, and it must be on the twist curve and in the subgroup of order R
+func (bn128 Bn128) CheckG2(p [3][2]*big.Int) error {
+	if !bn128.inField(p[0][0], p[0][1], p[1][0], p[1][1], p[2][0], p[2][1]) {
+		return errors.New("G2 point coordinate out of the field")
+	}
+	if !bn128.G2.IsOnCurve(p) {
+		return errors.New("G2 point not on the curve")
+	}
+	if !bn128.IsInG2Subgroup(p) {
+		return errors.New("G2 point not in the subgroup of order R")
+	}
+	return nil
+}
+
 func (bn128 *Bn128) preparePairing() error {
 	var ok bool
 	bn128.LoopCount, ok = new(big.Int).SetString("29793968203157093288", 10)
@@ -190,6 +230,58 @@ type AteG1Precomp struct {
 	Py *big.Int
 }
 
+// MultiPairing computes the product of the pairings e(g1s[i], g2s[i]), sharing the squarings of the Miller loop between all the pairs and doing only one final exponentiation. g1s and g2s must have the same length
+func (bn128 Bn128) MultiPairing(g1s [][3]*big.Int, g2s [][3][2]*big.Int) [2][3][2]*big.Int {
+	if len(g1s) != len(g2s) {
+		panic(errors.New("MultiPairing: different number of G1 and G2 points"))
+	}
+	var pre1s []AteG1Precomp
+	var pre2s []AteG2Precomp
+	for i := 0; i < len(g1s); i++ {
+		// e(0, Q) = e(P, 0) = 1
+		if bn128.G1.IsZero(g1s[i]) || bn128.G2.IsZero(g2s[i]) {
+			continue
+		}
+		pre1s = append(pre1s, bn128.preComputeG1(g1s[i]))
+		pre2s = append(pre2s, bn128.preComputeG2(g2s[i]))
+	}
+
+	r := bn128.multiMillerLoop(pre1s, pre2s)
+	return bn128.finalExponentiation(r)
+}
+
+// PreComputeG2 computes the coefficients of the lines of the Miller loop for the G2 point, which can be reused in different pairings with MultiPairingPrecomputed. The point must not be zero
+func (bn128 Bn128) PreComputeG2(p [3][2]*big.Int) AteG2Precomp {
+	return bn128.preComputeG2(p)
+}
+
+// MultiPairingPrecomputed computes the same than MultiPairing, but with the G2 points already precomputed with PreComputeG2
+func (bn128 Bn128) MultiPairingPrecomputed(g1s [][3]*big.Int, pre2s []AteG2Precomp) [2][3][2]*big.Int {
+	if len(g1s) != len(pre2s) {
+		panic(errors.New("MultiPairingPrecomputed: different number of G1 and G2 points"))
+	}
+	var pre1s []AteG1Precomp
+	var pre2sNonZero []AteG2Precomp
+	for i := 0; i < len(g1s); i++ {
+		if bn128.G1.IsZero(g1s[i]) {
+			continue
+		}
+		pre1s = append(pre1s, bn128.preComputeG1(g1s[i]))
+		pre2sNonZero = append(pre2sNonZero, pre2s[i])
+	}
+
+	r := bn128.multiMillerLoop(pre1s, pre2sNonZero)
+	return bn128.finalExponentiation(r)
+}
+
+// PairingCheck returns true if the product of the pairings e(g1s[i], g2s[i]) is equal to one
+func (bn128 Bn128) PairingCheck(g1s [][3]*big.Int, g2s [][3][2]*big.Int) bool {
+	if len(g1s) != len(g2s) {
+		return false
+	}
+	return bn128.Fq12.Equal(bn128.MultiPairing(g1s, g2s), bn128.Fq12.One())
+}
+
 func (bn128 Bn128) preComputeG1(p [3]*big.Int) AteG1Precomp {
 	pCopy := bn128.G1.Affine(p)
 	res := AteG1Precomp{
@@ -399,6 +491,40 @@ func (bn128 Bn128) MillerLoop(pre1 AteG1Precomp, pre2 AteG2Precomp) [2][3][2]*bi
 	return f
 }
 
+// multiMillerLoop computes the product of the Miller loops of the pairs, squaring the accumulated value only once at each step
+func (bn128 Bn128) multiMillerLoop(pre1s []AteG1Precomp, pre2s []AteG2Precomp) [2][3][2]*big.Int {
+	idx := 0
+	f := bn128.Fq12.One()
+
+	// adds the lines of the current step of all the pairs
+	mulLines := func() {
+		for j := 0; j < len(pre1s); j++ {
+			c := pre2s[j].Coeffs[idx]
+			f = bn128.mulBy024(
+				f,
+				c.Ell0,
+				bn128.Fq2.MulScalar(c.EllVW, pre1s[j].Py),
+				bn128.Fq2.MulScalar(c.EllVV, pre1s[j].Px))
+		}
+		idx++
+	}
+
+	for i := bn128.LoopCount.BitLen() - 2; i >= 0; i-- {
+		f = bn128.Fq12.Square(f)
+		mulLines()
+		if bn128.LoopCount.Bit(i) == 1 {
+			mulLines()
+		}
+	}
+	if bn128.LoopCountNeg {
+		f = bn128.Fq12.Inverse(f)
+	}
+
+	mulLines()
+	mulLines()
+	return f
+}
+
 func (bn128 Bn128) mulBy024(a [2][3][2]*big.Int, ell0, ellVW, ellVV [2]*big.Int) [2][3][2]*big.Int {
 	b := [2][3][2]*big.Int{
 		[3][2]*big.Int{
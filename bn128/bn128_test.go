@@ -86,3 +86,76 @@ func TestBN128Pairing2(t *testing.T) {
 	assert.True(t, bn.Fq12.Equal(gt6, bn.Pairing(bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(2))), bn.G2.MulScalar(bn.G2.G, big.NewInt(int64(3))))))
 
 }
+
+func TestBN128MultiPairing(t *testing.T) {
+	bn, err := NewBn128()
+	assert.Nil(t, err)
+
+	g1a := bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(3)))
+	g2a := bn.G2.MulScalar(bn.G2.G, big.NewInt(int64(5)))
+	g1b := bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(7)))
+	g2b := bn.G2.MulScalar(bn.G2.G, big.NewInt(int64(11)))
+
+	// e(3*g1, 5*g2) * e(7*g1, 11*g2) == e(g1, g2)^92
+	expected := bn.Fq12.Mul(bn.Pairing(g1a, g2a), bn.Pairing(g1b, g2b))
+	mp := bn.MultiPairing([][3]*big.Int{g1a, g1b}, [][3][2]*big.Int{g2a, g2b})
+	assert.True(t, bn.Fq12.Equal(expected, mp))
+	gt92 := bn.Fq12.Exp(bn.Pairing(bn.G1.G, bn.G2.G), big.NewInt(int64(92)))
+	assert.True(t, bn.Fq12.Equal(gt92, mp))
+
+	// e(3*g1, 5*g2) * e(-15*g1, g2) == 1
+	g1c := bn.G1.Neg(bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(15))))
+	assert.True(t, bn.PairingCheck([][3]*big.Int{g1a, g1c}, [][3][2]*big.Int{g2a, bn.G2.G}))
+	assert.True(t, !bn.PairingCheck([][3]*big.Int{g1a, g1b}, [][3][2]*big.Int{g2a, bn.G2.G}))
+
+	// the pairs with the zero point do not change the product
+	zero1 := [3]*big.Int{bn.G1.F.Zero(), bn.G1.F.Zero(), bn.G1.F.Zero()}
+	assert.True(t, bn.PairingCheck([][3]*big.Int{g1a, g1c, zero1, g1b}, [][3][2]*big.Int{g2a, bn.G2.G, g2b, bn.G2.Zero()}))
+	assert.True(t, bn.PairingCheck([][3]*big.Int{}, [][3][2]*big.Int{}))
+	assert.True(t, !bn.PairingCheck([][3]*big.Int{g1a}, [][3][2]*big.Int{}))
+}
+
+func TestBN128MultiPairingPrecomputed(t *testing.T) {
+	bn, err := NewBn128()
+	assert.Nil(t, err)
+
+	g1a := bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(3)))
+	g2a := bn.G2.MulScalar(bn.G2.G, big.NewInt(int64(5)))
+	pre2a := bn.PreComputeG2(g2a)
+	pre2 := bn.PreComputeG2(bn.G2.G)
+
+	expected := bn.MultiPairing([][3]*big.Int{g1a, bn.G1.G}, [][3][2]*big.Int{g2a, bn.G2.G})
+	assert.True(t, bn.Fq12.Equal(expected, bn.MultiPairingPrecomputed([][3]*big.Int{g1a, bn.G1.G}, []AteG2Precomp{pre2a, pre2})))
+	assert.True(t, bn.Fq12.Equal(bn.Pairing(g1a, g2a), bn.MultiPairingPrecomputed([][3]*big.Int{g1a}, []AteG2Precomp{pre2a})))
+}
+
+func TestBN128CheckPoints(t *testing.T) {
+	bn, err := NewBn128()
+	assert.Nil(t, err)
+
+	assert.Nil(t, bn.CheckG1(bn.G1.G))
+	assert.Nil(t, bn.CheckG1(bn.G1.MulScalar(bn.G1.G, big.NewInt(int64(7)))))
+	assert.NotNil(t, bn.CheckG1([3]*big.Int{big.NewInt(int64(1)), big.NewInt(int64(3)), big.NewInt(int64(1))}))
+	// same point, with a coordinate bigger than Q
+	assert.NotNil(t, bn.CheckG1([3]*big.Int{big.NewInt(int64(1)), new(big.Int).Add(bn.Q, big.NewInt(int64(2))), big.NewInt(int64(1))}))
+	assert.NotNil(t, bn.CheckG1([3]*big.Int{big.NewInt(int64(1)), nil, big.NewInt(int64(1))}))
+
+	assert.Nil(t, bn.CheckG2(bn.G2.G))
+	assert.Nil(t, bn.CheckG2(bn.G2.MulScalar(bn.G2.G, big.NewInt(int64(7)))))
+	assert.Nil(t, bn.CheckG2(bn.G2.Zero()))
+
+	// point on the twist curve that is not in the subgroup of order R
+	y0, ok := new(big.Int).SetString("18278151005453108793778860132295291098363647455926340152056652516292830556603", 10)
+	assert.True(t, ok)
+	y1, ok := new(big.Int).SetString("5912654199736721486680175016176231956195085055698687135131307249486702594212", 10)
+	assert.True(t, ok)
+	p := [3][2]*big.Int{
+		{big.NewInt(int64(1)), big.NewInt(int64(0))},
+		{y0, y1},
+		bn.G2.F.One(),
+	}
+	assert.True(t, bn.G2.IsOnCurve(p))
+	assert.True(t, !bn.IsInG2Subgroup(p))
+	assert.NotNil(t, bn.CheckG2(p))
+	assert.True(t, bn.IsInG2Subgroup(bn.G2.G))
+}
@@ -0,0 +1,94 @@
+package bn128
+
+import (
+	"math/big"
+	"sync"
+)
+
+// fixedBaseWindowSize returns the number of bits of the windows of the fixed base table used for n scalars
+func fixedBaseWindowSize(n int) uint {
+	switch {
+	case n < 32:
+		return 4
+	case n < 1024:
+		return 6
+	default:
+		return 8
+	}
+}
+
+// parallelFor calls f(i) for i in [0, n), splitting the range between the given number of goroutines
+func parallelFor(n, workers int, f func(i int)) {
+	bounds := chunkBounds(n, workers)
+	var wg sync.WaitGroup
+	wg.Add(len(bounds))
+	for _, b := range bounds {
+		go func(b [2]int) {
+			defer wg.Done()
+			for i := b[0]; i < b[1]; i++ {
+				f(i)
+			}
+		}(b)
+	}
+	wg.Wait()
+}
+
+// BatchMulScalar computes p * es[i] for all the scalars. It precomputes a table with the multiples d * 2^(c*k) * p for each window k of c bits, so each multiplication only needs one addition per window, and splits the scalars between the given number of goroutines
+func (g1 G1) BatchMulScalar(p [3]*big.Int, es []*big.Int, workers int) [][3]*big.Int {
+	c := fixedBaseWindowSize(len(es))
+	nWindows := (maxBitLen(es) + int(c) - 1) / int(c)
+
+	// table[k][d-1] = d * 2^(c*k) * p
+	table := make([][][3]*big.Int, nWindows)
+	base := p
+	for k := 0; k < nWindows; k++ {
+		table[k] = make([][3]*big.Int, (1<<c)-1)
+		table[k][0] = base
+		for d := 1; d < len(table[k]); d++ {
+			table[k][d] = g1.Add(table[k][d-1], base)
+		}
+		base = g1.Add(table[k][len(table[k])-1], base)
+	}
+
+	r := make([][3]*big.Int, len(es))
+	parallelFor(len(es), workers, func(i int) {
+		q := [3]*big.Int{g1.F.Zero(), g1.F.Zero(), g1.F.Zero()}
+		for k := 0; k < nWindows; k++ {
+			if v := windowValue(es[i], k, c); v != 0 {
+				q = g1.Add(q, table[k][v-1])
+			}
+		}
+		r[i] = q
+	})
+	return r
+}
+
+// BatchMulScalar computes p * es[i] for all the scalars. It precomputes a table with the multiples d * 2^(c*k) * p for each window k of c bits, so each multiplication only needs one addition per window, and splits the scalars between the given number of goroutines
+func (g2 G2) BatchMulScalar(p [3][2]*big.Int, es []*big.Int, workers int) [][3][2]*big.Int {
+	c := fixedBaseWindowSize(len(es))
+	nWindows := (maxBitLen(es) + int(c) - 1) / int(c)
+
+	// table[k][d-1] = d * 2^(c*k) * p
+	table := make([][][3][2]*big.Int, nWindows)
+	base := p
+	for k := 0; k < nWindows; k++ {
+		table[k] = make([][3][2]*big.Int, (1<<c)-1)
+		table[k][0] = base
+		for d := 1; d < len(table[k]); d++ {
+			table[k][d] = g2.Add(table[k][d-1], base)
+		}
+		base = g2.Add(table[k][len(table[k])-1], base)
+	}
+
+	r := make([][3][2]*big.Int, len(es))
+	parallelFor(len(es), workers, func(i int) {
+		q := [3][2]*big.Int{g2.F.Zero(), g2.F.Zero(), g2.F.Zero()}
+		for k := 0; k < nWindows; k++ {
+			if v := windowValue(es[i], k, c); v != 0 {
+				q = g2.Add(q, table[k][v-1])
+			}
+		}
+		r[i] = q
+	})
+	return r
+}
@@ -0,0 +1,44 @@
+package bn128
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestG1BatchMulScalar(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	es := []*big.Int{big.NewInt(int64(0)), big.NewInt(int64(1)), big.NewInt(int64(2)), big.NewInt(int64(255))}
+	for i := 0; i < 40; i++ {
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		es = append(es, e)
+	}
+	for _, workers := range []int{1, 4} {
+		ps := bn128.G1.BatchMulScalar(bn128.G1.G, es, workers)
+		assert.Equal(t, len(es), len(ps))
+		for i := range es {
+			assert.True(t, bn128.G1.Equal(bn128.G1.MulScalar(bn128.G1.G, es[i]), ps[i]))
+		}
+	}
+	assert.Equal(t, 0, len(bn128.G1.BatchMulScalar(bn128.G1.G, []*big.Int{}, 4)))
+}
+
+func TestG2BatchMulScalar(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	es := []*big.Int{big.NewInt(int64(0)), big.NewInt(int64(1)), big.NewInt(int64(15))}
+	for i := 0; i < 5; i++ {
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		es = append(es, e)
+	}
+	ps := bn128.G2.BatchMulScalar(bn128.G2.G, es, 2)
+	for i := range es {
+		assert.True(t, bn128.G2.Equal(bn128.G2.MulScalar(bn128.G2.G, es[i]), ps[i]))
+	}
+}
@@ -9,6 +9,7 @@ import (
 type G1 struct {
 	F fields.Fq
 	G [3]*big.Int
+	b *big.Int // coefficient of the curve equation y^2 = x^3 + b
 }
 
 func NewG1(f fields.Fq, g [2]*big.Int) G1 {
@@ -19,6 +20,8 @@ func NewG1(f fields.Fq, g [2]*big.Int) G1 {
 		g[1],
 		g1.F.One(),
 	}
+	// b = y^2 - x^3, from the generator
+	g1.b = g1.F.Sub(g1.F.Square(g[1]), g1.F.Mul(g1.F.Square(g[0]), g[0]))
 	return g1
 }
 
@@ -29,6 +32,18 @@ func (g1 G1) IsZero(p [3]*big.Int) bool {
 	return g1.F.IsZero(p[2])
 }
 
+// IsOnCurve returns true if the point satisfies the curve equation, in Jacobian coordinates y^2 = x^3 + b * z^6. The zero point is on the curve
+func (g1 G1) IsOnCurve(p [3]*big.Int) bool {
+	if g1.IsZero(p) {
+		return true
+	}
+	z2 := g1.F.Square(p[2])
+	z6 := g1.F.Mul(g1.F.Square(z2), z2)
+	y2 := g1.F.Square(p[1])
+	x3 := g1.F.Mul(g1.F.Square(p[0]), p[0])
+	return g1.F.Equal(y2, g1.F.Add(x3, g1.F.Mul(g1.b, z6)))
+}
+
 func (g1 G1) Add(p1, p2 [3]*big.Int) [3]*big.Int {
 
 	// https://en.wikibooks.org/wiki/Cryptography/Prime_Curve/Jacobian_Coordinates
@@ -62,10 +77,14 @@ func (g1 G1) Add(p1, p2 [3]*big.Int) [3]*big.Int {
 	s2 := g1.F.Mul(y2, t1)
 
 	h := g1.F.Sub(u2, u1)
+	t3 := g1.F.Sub(s2, s1)
+	if g1.F.IsZero(h) && g1.F.IsZero(t3) {
+		// p1 == p2, the addition formula does not work for this case
+		return g1.Double(p1)
+	}
 	t2 := g1.F.Add(h, h)
 	i := g1.F.Square(t2)
 	j := g1.F.Mul(h, i)
-	t3 := g1.F.Sub(s2, s1)
 	r := g1.F.Add(t3, t3)
 	v := g1.F.Mul(u1, i)
 	t4 := g1.F.Square(r)
@@ -169,6 +188,15 @@ func (g1 G1) Affine(p [3]*big.Int) [2]*big.Int {
 	return [2]*big.Int{x, y}
 }
 
+// Normalize returns the point in Jacobian coordinates with z = 1, or the zero point {0, 0, 0}, so equal points have the same representation
+func (g1 G1) Normalize(p [3]*big.Int) [3]*big.Int {
+	if g1.IsZero(p) {
+		return [3]*big.Int{g1.F.Zero(), g1.F.Zero(), g1.F.Zero()}
+	}
+	a := g1.Affine(p)
+	return [3]*big.Int{a[0], a[1], g1.F.One()}
+}
+
 func (g1 G1) Equal(p1, p2 [3]*big.Int) bool {
 	if g1.IsZero(p1) {
 		return g1.IsZero(p2)
@@ -29,3 +29,18 @@ func TestG1(t *testing.T) {
 	assert.Equal(t, "2f978c0ab89ebaa576866706b14787f360c4d6c3869efe5a72f7c3651a72ff00", hex.EncodeToString(a[0].Bytes()))
 	assert.Equal(t, "12e4ba7f0edca8b4fa668fe153aebd908d322dc26ad964d4cd314795844b62b2", hex.EncodeToString(a[1].Bytes()))
 }
+
+func TestG1IsOnCurve(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	assert.True(t, bn128.G1.IsOnCurve(bn128.G1.G))
+	p := bn128.G1.MulScalar(bn128.G1.G, big.NewInt(int64(1234)))
+	assert.True(t, bn128.G1.IsOnCurve(p)) // z != 1
+	assert.True(t, bn128.G1.IsOnCurve(bn128.G1.Normalize(p)))
+	assert.True(t, bn128.G1.IsOnCurve([3]*big.Int{bn128.G1.F.Zero(), bn128.G1.F.Zero(), bn128.G1.F.Zero()}))
+
+	assert.True(t, !bn128.G1.IsOnCurve([3]*big.Int{big.NewInt(int64(1)), big.NewInt(int64(3)), big.NewInt(int64(1))}))
+	p[0] = bn128.G1.F.Add(p[0], big.NewInt(int64(1)))
+	assert.True(t, !bn128.G1.IsOnCurve(p))
+}
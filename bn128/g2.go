@@ -9,6 +9,7 @@ import (
 type G2 struct {
 	F fields.Fq2
 	G [3][2]*big.Int
+	b [2]*big.Int // coefficient of the twist curve equation y^2 = x^3 + b
 }
 
 func NewG2(f fields.Fq2, g [2][2]*big.Int) G2 {
@@ -19,6 +20,8 @@ func NewG2(f fields.Fq2, g [2][2]*big.Int) G2 {
 		g[1],
 		g2.F.One(),
 	}
+	// b = y^2 - x^3, from the generator
+	g2.b = g2.F.Sub(g2.F.Square(g[1]), g2.F.Mul(g2.F.Square(g[0]), g[0]))
 	return g2
 }
 
@@ -29,6 +32,18 @@ func (g2 G2) IsZero(p [3][2]*big.Int) bool {
 	return g2.F.IsZero(p[2])
 }
 
+// IsOnCurve returns true if the point satisfies the twist curve equation, in Jacobian coordinates y^2 = x^3 + b * z^6. The zero point is on the curve. Points on the curve are not always in the subgroup of order R, see Bn128.IsInG2Subgroup
+func (g2 G2) IsOnCurve(p [3][2]*big.Int) bool {
+	if g2.IsZero(p) {
+		return true
+	}
+	z2 := g2.F.Square(p[2])
+	z6 := g2.F.Mul(g2.F.Square(z2), z2)
+	y2 := g2.F.Square(p[1])
+	x3 := g2.F.Mul(g2.F.Square(p[0]), p[0])
+	return g2.F.Equal(y2, g2.F.Add(x3, g2.F.Mul(g2.b, z6)))
+}
+
 func (g2 G2) Add(p1, p2 [3][2]*big.Int) [3][2]*big.Int {
 
 	// https://en.wikibooks.org/wiki/Cryptography/Prime_Curve/Jacobian_Coordinates
@@ -62,10 +77,14 @@ func (g2 G2) Add(p1, p2 [3][2]*big.Int) [3][2]*big.Int {
 	s2 := g2.F.Mul(y2, t1)
 
 	h := g2.F.Sub(u2, u1)
+	t3 := g2.F.Sub(s2, s1)
+	if g2.F.IsZero(h) && g2.F.IsZero(t3) {
+		// p1 == p2, the addition formula does not work for this case
+		return g2.Double(p1)
+	}
 	t2 := g2.F.Add(h, h)
 	i := g2.F.Square(t2)
 	j := g2.F.Mul(h, i)
-	t3 := g2.F.Sub(s2, s1)
 	r := g2.F.Add(t3, t3)
 	v := g2.F.Mul(u1, i)
 	t4 := g2.F.Square(r)
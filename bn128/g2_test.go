@@ -22,3 +22,17 @@ func TestG2(t *testing.T) {
 	grsum2 := bn128.G2.Affine(bn128.G2.MulScalar(bn128.G2.G, r1r2))
 	assert.True(t, bn128.G2.Equal(grsum1, grsum2))
 }
+
+func TestG2IsOnCurve(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	assert.True(t, bn128.G2.IsOnCurve(bn128.G2.G))
+	p := bn128.G2.MulScalar(bn128.G2.G, big.NewInt(int64(1234)))
+	assert.True(t, bn128.G2.IsOnCurve(p)) // z != 1
+	assert.True(t, bn128.G2.IsOnCurve(bn128.G2.Affine(p)))
+	assert.True(t, bn128.G2.IsOnCurve(bn128.G2.Zero()))
+
+	p[1] = bn128.G2.F.Add(p[1], bn128.G2.F.One())
+	assert.True(t, !bn128.G2.IsOnCurve(p))
+}
@@ -0,0 +1,231 @@
+package bn128
+
+import (
+	"errors"
+	"math/big"
+)
+
+// sizes in bytes of the encodings of the elements and the points
+const (
+	fqSize           = 32
+	G1Size           = 2 * fqSize
+	G1CompressedSize = fqSize
+	G2Size           = 4 * fqSize
+	G2CompressedSize = 2 * fqSize
+	flagInfinity     = 0x80 // set in the first byte for the zero point
+	flagLargestY     = 0x40 // set in the first byte of the compressed encoding when y is the lexicographically largest of the two roots
+	flagsMask        = flagInfinity | flagLargestY
+)
+
+const errInvalidEncoding = "invalid point encoding"
+
+// putFq writes the element in big-endian on the fqSize bytes of b
+func putFq(b []byte, a *big.Int) {
+	a.FillBytes(b[:fqSize])
+}
+
+// isLargest returns true if a > (q-1)/2, that is, if a is the largest of a and -a
+func isLargest(a, q *big.Int) bool {
+	return a.Cmp(new(big.Int).Rsh(q, 1)) > 0
+}
+
+// isLargestFq2 returns true if the element is the lexicographically largest, comparing first the imaginary part
+func (g2 G2) isLargestFq2(a [2]*big.Int) bool {
+	if !g2.F.F.IsZero(a[1]) {
+		return isLargest(a[1], g2.F.F.Q)
+	}
+	return isLargest(a[0], g2.F.F.Q)
+}
+
+// readFq reads an element from the fqSize bytes of b, with the flag bits already cleared, and checks that it is in the field
+func readFq(b []byte, q *big.Int) (*big.Int, error) {
+	a := new(big.Int).SetBytes(b[:fqSize])
+	if a.Cmp(q) >= 0 {
+		return nil, errors.New(errInvalidEncoding + ": coordinate out of the field")
+	}
+	return a, nil
+}
+
+// readFlags returns the flags of the encoding and a copy of it with the flag bits cleared. The zero point must have all the other bits at zero
+func readFlags(b []byte) (byte, []byte, error) {
+	flags := b[0] & flagsMask
+	data := make([]byte, len(b))
+	copy(data, b)
+	data[0] &^= flagsMask
+	if flags&flagInfinity != 0 {
+		if flags&flagLargestY != 0 {
+			return 0, nil, errors.New(errInvalidEncoding + ": zero point with y flag")
+		}
+		for _, v := range data {
+			if v != 0 {
+				return 0, nil, errors.New(errInvalidEncoding + ": zero point with non zero coordinates")
+			}
+		}
+	}
+	return flags, data, nil
+}
+
+// Marshal encodes the point in G1Size bytes, as the affine coordinates x, y in big-endian. The zero point is encoded with the infinity flag in the first byte
+func (g1 G1) Marshal(p [3]*big.Int) []byte {
+	b := make([]byte, G1Size)
+	if g1.IsZero(p) {
+		b[0] = flagInfinity
+		return b
+	}
+	a := g1.Affine(p)
+	putFq(b, a[0])
+	putFq(b[fqSize:], a[1])
+	return b
+}
+
+// MarshalCompressed encodes the point in G1CompressedSize bytes, as the affine coordinate x in big-endian, and the flag of the y root in the first byte
+func (g1 G1) MarshalCompressed(p [3]*big.Int) []byte {
+	b := make([]byte, G1CompressedSize)
+	if g1.IsZero(p) {
+		b[0] = flagInfinity
+		return b
+	}
+	a := g1.Affine(p)
+	putFq(b, a[0])
+	if isLargest(a[1], g1.F.Q) {
+		b[0] |= flagLargestY
+	}
+	return b
+}
+
+// Unmarshal decodes a point from the encodings of Marshal or MarshalCompressed, depending on the length of b. It returns an error if the point is not on the curve
+func (g1 G1) Unmarshal(b []byte) ([3]*big.Int, error) {
+	if len(b) != G1Size && len(b) != G1CompressedSize {
+		return [3]*big.Int{}, errors.New(errInvalidEncoding + ": wrong G1 length")
+	}
+	flags, data, err := readFlags(b)
+	if err != nil {
+		return [3]*big.Int{}, err
+	}
+	if flags&flagInfinity != 0 {
+		return [3]*big.Int{g1.F.Zero(), g1.F.Zero(), g1.F.Zero()}, nil
+	}
+	x, err := readFq(data, g1.F.Q)
+	if err != nil {
+		return [3]*big.Int{}, err
+	}
+
+	var y *big.Int
+	if len(b) == G1Size {
+		if flags != 0 {
+			return [3]*big.Int{}, errors.New(errInvalidEncoding + ": y flag in uncompressed G1")
+		}
+		y, err = readFq(data[fqSize:], g1.F.Q)
+		if err != nil {
+			return [3]*big.Int{}, err
+		}
+	} else {
+		// y^2 = x^3 + b
+		var ok bool
+		y, ok = g1.F.Sqrt(g1.F.Add(g1.F.Mul(g1.F.Square(x), x), g1.b))
+		if !ok {
+			return [3]*big.Int{}, errors.New(errInvalidEncoding + ": G1 point not on the curve")
+		}
+		if isLargest(y, g1.F.Q) != (flags&flagLargestY != 0) {
+			y = g1.F.Neg(y)
+		}
+	}
+
+	p := [3]*big.Int{x, y, g1.F.One()}
+	if !g1.IsOnCurve(p) {
+		return [3]*big.Int{}, errors.New(errInvalidEncoding + ": G1 point not on the curve")
+	}
+	return p, nil
+}
+
+// putFq2 writes the element on the 2*fqSize bytes of b, the imaginary part first
+func putFq2(b []byte, a [2]*big.Int) {
+	putFq(b, a[1])
+	putFq(b[fqSize:], a[0])
+}
+
+// readFq2 reads an element written by putFq2
+func readFq2(b []byte, q *big.Int) ([2]*big.Int, error) {
+	c1, err := readFq(b, q)
+	if err != nil {
+		return [2]*big.Int{}, err
+	}
+	c0, err := readFq(b[fqSize:], q)
+	if err != nil {
+		return [2]*big.Int{}, err
+	}
+	return [2]*big.Int{c0, c1}, nil
+}
+
+// Marshal encodes the point in G2Size bytes, as the affine coordinates x, y, each one with the imaginary part first, in big-endian. The zero point is encoded with the infinity flag in the first byte
+func (g2 G2) Marshal(p [3][2]*big.Int) []byte {
+	b := make([]byte, G2Size)
+	if g2.IsZero(p) {
+		b[0] = flagInfinity
+		return b
+	}
+	a := g2.Affine(p)
+	putFq2(b, a[0])
+	putFq2(b[2*fqSize:], a[1])
+	return b
+}
+
+// MarshalCompressed encodes the point in G2CompressedSize bytes, as the affine coordinate x with the imaginary part first in big-endian, and the flag of the y root in the first byte
+func (g2 G2) MarshalCompressed(p [3][2]*big.Int) []byte {
+	b := make([]byte, G2CompressedSize)
+	if g2.IsZero(p) {
+		b[0] = flagInfinity
+		return b
+	}
+	a := g2.Affine(p)
+	putFq2(b, a[0])
+	if g2.isLargestFq2(a[1]) {
+		b[0] |= flagLargestY
+	}
+	return b
+}
+
+// Unmarshal decodes a point from the encodings of Marshal or MarshalCompressed, depending on the length of b. It returns an error if the point is not on the twist curve. The subgroup membership is not checked here, see Bn128.CheckG2
+func (g2 G2) Unmarshal(b []byte) ([3][2]*big.Int, error) {
+	if len(b) != G2Size && len(b) != G2CompressedSize {
+		return [3][2]*big.Int{}, errors.New(errInvalidEncoding + ": wrong G2 length")
+	}
+	flags, data, err := readFlags(b)
+	if err != nil {
+		return [3][2]*big.Int{}, err
+	}
+	if flags&flagInfinity != 0 {
+		return g2.Zero(), nil
+	}
+	x, err := readFq2(data, g2.F.F.Q)
+	if err != nil {
+		return [3][2]*big.Int{}, err
+	}
+
+	var y [2]*big.Int
+	if len(b) == G2Size {
+		if flags != 0 {
+			return [3][2]*big.Int{}, errors.New(errInvalidEncoding + ": y flag in uncompressed G2")
+		}
+		y, err = readFq2(data[2*fqSize:], g2.F.F.Q)
+		if err != nil {
+			return [3][2]*big.Int{}, err
+		}
+	} else {
+		// y^2 = x^3 + b
+		var ok bool
+		y, ok = g2.F.Sqrt(g2.F.Add(g2.F.Mul(g2.F.Square(x), x), g2.b))
+		if !ok {
+			return [3][2]*big.Int{}, errors.New(errInvalidEncoding + ": G2 point not on the curve")
+		}
+		if g2.isLargestFq2(y) != (flags&flagLargestY != 0) {
+			y = g2.F.Affine(g2.F.Neg(y))
+		}
+	}
+
+	p := [3][2]*big.Int{x, y, g2.F.One()}
+	if !g2.IsOnCurve(p) {
+		return [3][2]*big.Int{}, errors.New(errInvalidEncoding + ": G2 point not on the curve")
+	}
+	return p, nil
+}
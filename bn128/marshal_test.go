@@ -0,0 +1,104 @@
+package bn128
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestG1Marshal(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	ps := [][3]*big.Int{bn128.G1.G, {bn128.G1.F.Zero(), bn128.G1.F.Zero(), bn128.G1.F.Zero()}}
+	for i := 0; i < 10; i++ {
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		ps = append(ps, bn128.G1.MulScalar(bn128.G1.G, e))
+	}
+	for _, p := range ps {
+		b := bn128.G1.Marshal(p)
+		assert.Equal(t, G1Size, len(b))
+		q, err := bn128.G1.Unmarshal(b)
+		assert.Nil(t, err)
+		assert.True(t, bn128.G1.Equal(p, q))
+
+		bc := bn128.G1.MarshalCompressed(p)
+		assert.Equal(t, G1CompressedSize, len(bc))
+		q, err = bn128.G1.Unmarshal(bc)
+		assert.Nil(t, err)
+		assert.True(t, bn128.G1.Equal(p, q))
+		q, err = bn128.G1.Unmarshal(bn128.G1.MarshalCompressed(bn128.G1.Neg(p)))
+		assert.Nil(t, err)
+		assert.True(t, bn128.G1.Equal(bn128.G1.Neg(p), q))
+	}
+
+	// wrong length
+	_, err = bn128.G1.Unmarshal(make([]byte, 33))
+	assert.NotNil(t, err)
+	// point not on the curve
+	b := bn128.G1.Marshal(bn128.G1.G)
+	b[G1Size-1]++
+	_, err = bn128.G1.Unmarshal(b)
+	assert.NotNil(t, err)
+	// y flag in the uncompressed encoding
+	b = bn128.G1.Marshal(bn128.G1.G)
+	b[0] |= flagLargestY
+	_, err = bn128.G1.Unmarshal(b)
+	assert.NotNil(t, err)
+	// coordinate bigger than Q
+	b = make([]byte, G1CompressedSize)
+	putFq(b, new(big.Int).Add(bn128.Q, big.NewInt(int64(1))))
+	_, err = bn128.G1.Unmarshal(b)
+	assert.NotNil(t, err)
+	// zero point with other bits set
+	b = make([]byte, G1CompressedSize)
+	b[0] = flagInfinity
+	b[5] = 1
+	_, err = bn128.G1.Unmarshal(b)
+	assert.NotNil(t, err)
+	// x without y on the curve, x^3 + 3 is not a square for x = 4
+	b = make([]byte, G1CompressedSize)
+	b[G1CompressedSize-1] = 4
+	_, err = bn128.G1.Unmarshal(b)
+	assert.NotNil(t, err)
+}
+
+func TestG2Marshal(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	ps := [][3][2]*big.Int{bn128.G2.G, bn128.G2.Zero()}
+	for i := 0; i < 5; i++ {
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		ps = append(ps, bn128.G2.MulScalar(bn128.G2.G, e))
+	}
+	for _, p := range ps {
+		b := bn128.G2.Marshal(p)
+		assert.Equal(t, G2Size, len(b))
+		q, err := bn128.G2.Unmarshal(b)
+		assert.Nil(t, err)
+		assert.True(t, bn128.G2.Equal(p, q))
+
+		bc := bn128.G2.MarshalCompressed(p)
+		assert.Equal(t, G2CompressedSize, len(bc))
+		q, err = bn128.G2.Unmarshal(bc)
+		assert.Nil(t, err)
+		assert.True(t, bn128.G2.Equal(p, q))
+		q, err = bn128.G2.Unmarshal(bn128.G2.MarshalCompressed(bn128.G2.Neg(p)))
+		assert.Nil(t, err)
+		assert.True(t, bn128.G2.Equal(bn128.G2.Neg(p), q))
+	}
+
+	// the imaginary part goes first
+	b := bn128.G2.Marshal(bn128.G2.G)
+	assert.Equal(t, bn128.Gg2[0][1].Bytes(), new(big.Int).SetBytes(b[:32]).Bytes())
+
+	_, err = bn128.G2.Unmarshal(make([]byte, G1Size+1))
+	assert.NotNil(t, err)
+	b[G2Size-1]++
+	_, err = bn128.G2.Unmarshal(b)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,182 @@
+package bn128
+
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+// multiExpWindowSize returns the number of bits of the windows used by the bucket method for n points
+func multiExpWindowSize(n int) uint {
+	if n < 32 {
+		return 3
+	}
+	return uint(math.Ceil(math.Log(float64(n))))
+}
+
+// maxBitLen returns the biggest bit length of the scalars
+func maxBitLen(es []*big.Int) int {
+	m := 0
+	for _, e := range es {
+		if e.BitLen() > m {
+			m = e.BitLen()
+		}
+	}
+	return m
+}
+
+// windowValue returns the value of the bits [w*c, (w+1)*c) of the scalar e
+func windowValue(e *big.Int, w int, c uint) int {
+	v := 0
+	for k := uint(0); k < c; k++ {
+		v |= int(e.Bit(w*int(c)+int(k))) << k
+	}
+	return v
+}
+
+// MultiExp computes Σ ps[i] * es[i] using the bucket method (Pippenger), which is much faster than adding the MulScalar of each term
+func (g1 G1) MultiExp(ps [][3]*big.Int, es []*big.Int) [3]*big.Int {
+	zero := [3]*big.Int{g1.F.Zero(), g1.F.Zero(), g1.F.Zero()}
+	n := len(ps)
+	if len(es) < n {
+		n = len(es)
+	}
+	c := multiExpWindowSize(n)
+	nWindows := (maxBitLen(es[:n]) + int(c) - 1) / int(c)
+
+	q := zero
+	buckets := make([][3]*big.Int, (1<<c)-1)
+	for w := nWindows - 1; w >= 0; w-- {
+		for k := uint(0); k < c; k++ {
+			q = g1.Double(q)
+		}
+
+		// each point is added to the bucket of the value of its scalar in the current window
+		for j := range buckets {
+			buckets[j] = zero
+		}
+		for i := 0; i < n; i++ {
+			v := windowValue(es[i], w, c)
+			if v == 0 {
+				continue
+			}
+			buckets[v-1] = g1.Add(buckets[v-1], ps[i])
+		}
+
+		// Σ (j+1) * buckets[j], computed with running sums
+		sum := zero
+		acc := zero
+		for j := len(buckets) - 1; j >= 0; j-- {
+			sum = g1.Add(sum, buckets[j])
+			acc = g1.Add(acc, sum)
+		}
+		q = g1.Add(q, acc)
+	}
+	return q
+}
+
+// MultiExp computes Σ ps[i] * es[i] using the bucket method (Pippenger), which is much faster than adding the MulScalar of each term
+func (g2 G2) MultiExp(ps [][3][2]*big.Int, es []*big.Int) [3][2]*big.Int {
+	zero := [3][2]*big.Int{g2.F.Zero(), g2.F.Zero(), g2.F.Zero()}
+	n := len(ps)
+	if len(es) < n {
+		n = len(es)
+	}
+	c := multiExpWindowSize(n)
+	nWindows := (maxBitLen(es[:n]) + int(c) - 1) / int(c)
+
+	q := zero
+	buckets := make([][3][2]*big.Int, (1<<c)-1)
+	for w := nWindows - 1; w >= 0; w-- {
+		for k := uint(0); k < c; k++ {
+			q = g2.Double(q)
+		}
+
+		// each point is added to the bucket of the value of its scalar in the current window
+		for j := range buckets {
+			buckets[j] = zero
+		}
+		for i := 0; i < n; i++ {
+			v := windowValue(es[i], w, c)
+			if v == 0 {
+				continue
+			}
+			buckets[v-1] = g2.Add(buckets[v-1], ps[i])
+		}
+
+		// Σ (j+1) * buckets[j], computed with running sums
+		sum := zero
+		acc := zero
+		for j := len(buckets) - 1; j >= 0; j-- {
+			sum = g2.Add(sum, buckets[j])
+			acc = g2.Add(acc, sum)
+		}
+		q = g2.Add(q, acc)
+	}
+	return q
+}
+
+// chunkBounds returns the bounds of the chunks in which n elements are split to be processed by the given number of workers
+func chunkBounds(n, workers int) [][2]int {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	var bounds [][2]int
+	for i := 0; i < workers; i++ {
+		bounds = append(bounds, [2]int{i * n / workers, (i + 1) * n / workers})
+	}
+	return bounds
+}
+
+// MultiExpParallel computes Σ ps[i] * es[i] splitting the points in chunks that are computed with MultiExp in different goroutines. The result is normalized with Normalize, so it does not depend on the number of workers
+func (g1 G1) MultiExpParallel(ps [][3]*big.Int, es []*big.Int, workers int) [3]*big.Int {
+	n := len(ps)
+	if len(es) < n {
+		n = len(es)
+	}
+	bounds := chunkBounds(n, workers)
+	partial := make([][3]*big.Int, len(bounds))
+	var wg sync.WaitGroup
+	wg.Add(len(bounds))
+	for i, b := range bounds {
+		go func(i int, b [2]int) {
+			defer wg.Done()
+			partial[i] = g1.MultiExp(ps[b[0]:b[1]], es[b[0]:b[1]])
+		}(i, b)
+	}
+	wg.Wait()
+
+	q := [3]*big.Int{g1.F.Zero(), g1.F.Zero(), g1.F.Zero()}
+	for i := 0; i < len(partial); i++ {
+		q = g1.Add(q, partial[i])
+	}
+	return g1.Normalize(q)
+}
+
+// MultiExpParallel computes Σ ps[i] * es[i] splitting the points in chunks that are computed with MultiExp in different goroutines. The result is in affine coordinates, so it does not depend on the number of workers
+func (g2 G2) MultiExpParallel(ps [][3][2]*big.Int, es []*big.Int, workers int) [3][2]*big.Int {
+	n := len(ps)
+	if len(es) < n {
+		n = len(es)
+	}
+	bounds := chunkBounds(n, workers)
+	partial := make([][3][2]*big.Int, len(bounds))
+	var wg sync.WaitGroup
+	wg.Add(len(bounds))
+	for i, b := range bounds {
+		go func(i int, b [2]int) {
+			defer wg.Done()
+			partial[i] = g2.MultiExp(ps[b[0]:b[1]], es[b[0]:b[1]])
+		}(i, b)
+	}
+	wg.Wait()
+
+	q := [3][2]*big.Int{g2.F.Zero(), g2.F.Zero(), g2.F.Zero()}
+	for i := 0; i < len(partial); i++ {
+		q = g2.Add(q, partial[i])
+	}
+	return g2.Affine(q)
+}
@@ -0,0 +1,69 @@
+package bn128
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestG1MultiExp(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	var ps [][3]*big.Int
+	var es []*big.Int
+	expected := [3]*big.Int{bn128.G1.F.Zero(), bn128.G1.F.Zero(), bn128.G1.F.Zero()}
+	for i := 0; i < 40; i++ {
+		p := bn128.G1.MulScalar(bn128.G1.G, big.NewInt(int64(i+1)))
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		if i%7 == 0 {
+			// repeated points and zero scalars
+			p = bn128.G1.G
+			e = big.NewInt(int64(0))
+		}
+		ps = append(ps, p)
+		es = append(es, e)
+		expected = bn128.G1.Add(expected, bn128.G1.MulScalar(p, e))
+	}
+	assert.True(t, bn128.G1.Equal(expected, bn128.G1.MultiExp(ps, es)))
+
+	// with the same point twice, the buckets have to add equal points
+	q := bn128.G1.MultiExp([][3]*big.Int{bn128.G1.G, bn128.G1.G}, []*big.Int{big.NewInt(int64(3)), big.NewInt(int64(3))})
+	assert.True(t, bn128.G1.Equal(bn128.G1.MulScalar(bn128.G1.G, big.NewInt(int64(6))), q))
+
+	assert.True(t, bn128.G1.IsZero(bn128.G1.MultiExp([][3]*big.Int{}, []*big.Int{})))
+
+	// same result for any number of workers
+	expectedNormalized := bn128.G1.Normalize(expected)
+	for _, workers := range []int{1, 3, 8, 100} {
+		assert.Equal(t, expectedNormalized, bn128.G1.MultiExpParallel(ps, es, workers))
+	}
+}
+
+func TestG2MultiExp(t *testing.T) {
+	bn128, err := NewBn128()
+	assert.Nil(t, err)
+
+	var ps [][3][2]*big.Int
+	var es []*big.Int
+	expected := [3][2]*big.Int{bn128.G2.F.Zero(), bn128.G2.F.Zero(), bn128.G2.F.Zero()}
+	for i := 0; i < 10; i++ {
+		p := bn128.G2.MulScalar(bn128.G2.G, big.NewInt(int64(i+1)))
+		e, err := bn128.Fq1.Rand()
+		assert.Nil(t, err)
+		ps = append(ps, p)
+		es = append(es, e)
+		expected = bn128.G2.Add(expected, bn128.G2.MulScalar(p, e))
+	}
+	assert.True(t, bn128.G2.Equal(expected, bn128.G2.MultiExp(ps, es)))
+
+	// same result for any number of workers
+	for _, workers := range []int{1, 3, 8} {
+		assert.Equal(t, bn128.G2.Affine(expected), bn128.G2.MultiExpParallel(ps, es, workers))
+	}
+
+	q := bn128.G2.MultiExp([][3][2]*big.Int{bn128.G2.G, bn128.G2.G}, []*big.Int{big.NewInt(int64(3)), big.NewInt(int64(3))})
+	assert.True(t, bn128.G2.Equal(bn128.G2.MulScalar(bn128.G2.G, big.NewInt(int64(6))), q))
+}
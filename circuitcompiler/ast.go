@@ -0,0 +1,161 @@
+package circuitcompiler
+
+import (
+	"math/big"
+)
+
+// Node is a node of the AST of the circuit code
+type Node interface {
+	Position() Pos
+}
+
+// Expr is an expression node
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Stmt is a statement node
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Ident is a signal name
+type Ident struct {
+	Pos  Pos
+	Name string
+}
+
+// Number is a constant value
+type Number struct {
+	Pos   Pos
+	Value *big.Int
+}
+
+// IndexExpr is an element of an array of signals, Name[Indexes[0]][Indexes[1]]...
+type IndexExpr struct {
+	Pos     Pos
+	Name    string
+	Indexes []Expr
+}
+
+// BinaryExpr is an operation X Op Y
+type BinaryExpr struct {
+	Pos Pos // position of the operator
+	Op  Token
+	X   Expr
+	Y   Expr
+}
+
+// UnaryExpr is an operation Op X
+type UnaryExpr struct {
+	Pos Pos
+	Op  Token
+	X   Expr
+}
+
+// CallExpr is a call to a function, or to a built-in like equals
+type CallExpr struct {
+	Pos  Pos
+	Func string
+	Args []Expr
+}
+
+// CondExpr is the selection Cond ? X : Y
+type CondExpr struct {
+	Pos  Pos // position of the ?
+	Cond Expr
+	X    Expr
+	Y    Expr
+}
+
+// AssignStmt is the statement Out = X
+type AssignStmt struct {
+	Pos Pos
+	Out string
+	X   Expr
+}
+
+// CallStmt is a call without assignment, as equals(a, b)
+type CallStmt struct {
+	Call *CallExpr
+}
+
+// ReturnStmt is the return statement of a function
+type ReturnStmt struct {
+	Pos Pos
+	X   Expr
+}
+
+// IfStmt is the statement if Cond { Then } else { Else }, an else if is an Else with a single IfStmt
+type IfStmt struct {
+	Pos  Pos
+	Cond Expr
+	Then []Stmt
+	Else []Stmt
+}
+
+// ForStmt is the loop for Counter in From..To: Body, which is unrolled with the Counter as a constant from From to To-1
+type ForStmt struct {
+	Pos     Pos
+	Counter string
+	From    Expr
+	To      Expr
+	Body    []Stmt
+}
+
+// Param is an input of a function, which is an array of Len signals if Len is not 0
+type Param struct {
+	Pos    Pos
+	Name   string
+	Public bool
+	Len    int
+}
+
+// FuncDecl is the declaration of a function, the circuit is the function main
+type FuncDecl struct {
+	Pos    Pos
+	Path   string // file of the declaration, empty for the main code
+	Name   string
+	Params []Param
+	Body   []Stmt
+}
+
+// ImportDecl is the import of the functions of another circuit file
+type ImportDecl struct {
+	Pos  Pos
+	Path string
+}
+
+// File is the AST of a circuit file
+type File struct {
+	Imports []*ImportDecl
+	Funcs   []*FuncDecl
+}
+
+func (x *Ident) Position() Pos      { return x.Pos }
+func (x *Number) Position() Pos     { return x.Pos }
+func (x *IndexExpr) Position() Pos  { return x.Pos }
+func (x *BinaryExpr) Position() Pos { return x.Pos }
+func (x *UnaryExpr) Position() Pos  { return x.Pos }
+func (x *CallExpr) Position() Pos   { return x.Pos }
+func (x *CondExpr) Position() Pos   { return x.Pos }
+func (s *AssignStmt) Position() Pos { return s.Pos }
+func (s *CallStmt) Position() Pos   { return s.Call.Pos }
+func (s *ReturnStmt) Position() Pos { return s.Pos }
+func (s *IfStmt) Position() Pos     { return s.Pos }
+func (s *ForStmt) Position() Pos    { return s.Pos }
+
+func (*Ident) exprNode()      {}
+func (*Number) exprNode()     {}
+func (*IndexExpr) exprNode()  {}
+func (*BinaryExpr) exprNode() {}
+func (*UnaryExpr) exprNode()  {}
+func (*CallExpr) exprNode()   {}
+func (*CondExpr) exprNode()   {}
+func (*AssignStmt) stmtNode() {}
+func (*CallStmt) stmtNode()   {}
+func (*ReturnStmt) stmtNode() {}
+func (*IfStmt) stmtNode()     {}
+func (*ForStmt) stmtNode()    {}
@@ -0,0 +1,197 @@
+package circuitcompiler
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// maxBits is the maximum number of bits of a bit decomposition, so its packing can not overflow the field
+var maxBits = r1csPrime.BitLen() - 1
+
+// elementName returns the name of the signal of an element of an array
+func elementName(name string, i int) string {
+	return name + "[" + strconv.Itoa(i) + "]"
+}
+
+// constInt returns the value of an expression that must be a constant, as the index of an array
+func (b *builder) constInt(x Expr, what string) (int, error) {
+	v, err := b.lowerExpr(x, what)
+	if err != nil {
+		return 0, err
+	}
+	k, ok := v.constant()
+	if !ok || !k.IsInt64() {
+		return 0, b.errorf(x.Position(), "the "+what+" must be a constant")
+	}
+	return int(k.Int64()), nil
+}
+
+// element returns the signal of an element of an array, which can be an array of arrays
+func (b *builder) element(x *IndexExpr) (string, error) {
+	s, name, err := b.indexed(x)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := b.arrays[s]; ok {
+		return "", b.errorf(x.Pos, name+" is an array, its elements are used as "+name+"[i]")
+	}
+	if !b.assigned[s] {
+		return "", b.errorf(x.Pos, "using signal "+name+" before it's set")
+	}
+	return s, nil
+}
+
+// indexed returns the signal of the indexed element of an array, and its name in the errors
+func (b *builder) indexed(x *IndexExpr) (string, string, error) {
+	s := b.resolve(x.Name)
+	if _, ok := b.arrays[s]; !ok {
+		// array of the elements of the iterations of a loop
+		s = x.Name
+	}
+	name := x.Name // name of the element in the errors
+	for _, index := range x.Indexes {
+		n, ok := b.arrays[s]
+		if !ok {
+			return "", "", b.errorf(x.Pos, name+" is not an array")
+		}
+		i, err := b.constInt(index, "index")
+		if err != nil {
+			return "", "", err
+		}
+		if i < 0 || i >= n {
+			return "", "", b.errorf(index.Position(), "index "+strconv.Itoa(i)+" out of range of "+name+"["+strconv.Itoa(n)+"]")
+		}
+		s, name = elementName(s, i), elementName(name, i)
+	}
+	return s, name, nil
+}
+
+// array returns the signal of the expression x and if it is an array, which is the case of an identifier or an indexed element of an array of arrays
+func (b *builder) array(x Expr) (string, bool, error) {
+	var s string
+	switch x := x.(type) {
+	case *Ident:
+		if _, ok := b.consts[x.Name]; ok {
+			return "", false, nil
+		}
+		if s = b.resolve(x.Name); !b.isArray(s) {
+			s = x.Name
+		}
+	case *IndexExpr:
+		var err error
+		if s, _, err = b.indexed(x); err != nil {
+			return "", false, err
+		}
+	default:
+		return "", false, nil
+	}
+	return s, b.isArray(s), nil
+}
+
+func (b *builder) isArray(s string) bool {
+	_, ok := b.arrays[s]
+	return ok
+}
+
+// declareParamArray declares the array of an input, returning the signals of its elements
+func (b *builder) declareParamArray(param Param) []string {
+	if b.arrays == nil {
+		b.arrays = make(map[string]int)
+	}
+	b.arrays[param.Name] = param.Len
+	var elements []string
+	for i := 0; i < param.Len; i++ {
+		elements = append(elements, elementName(param.Name, i))
+	}
+	return elements
+}
+
+// arrayArg returns the array of the argument of the array input param of the function fn, which must have the same length
+func (b *builder) arrayArg(arg Expr, fn string, param Param) (string, error) {
+	s, ok, err := b.array(arg)
+	if err != nil {
+		return "", err
+	}
+	if !ok || b.arrays[s] != param.Len {
+		return "", b.errorf(arg.Position(), "the argument "+param.Name+" of "+fn+" must be an array of "+strconv.Itoa(param.Len)+" signals")
+	}
+	for i := 0; i < param.Len; i++ {
+		element := elementName(s, i)
+		if b.isArray(element) {
+			return "", b.errorf(arg.Position(), "the argument "+param.Name+" of "+fn+" must be an array of "+strconv.Itoa(param.Len)+" signals")
+		}
+		if !b.assigned[element] {
+			return "", b.errorf(arg.Position(), "using signal "+element+" before it's set")
+		}
+	}
+	return s, nil
+}
+
+// declareArray declares the array of n signals, which are assigned by the caller
+func (b *builder) declareArray(pos Pos, name string, n int) error {
+	if _, ok := b.arrays[name]; ok || b.assigned[name] {
+		return b.errorf(pos, "signal "+name+" already assigned")
+	}
+	if b.arrays == nil {
+		b.arrays = make(map[string]int)
+	}
+	b.arrays[name] = n
+	return nil
+}
+
+// assertBool adds the constraint x * (x - 1) = 0
+func (b *builder) assertBool(x LinearCombination) {
+	b.assert(x, addLinearCombinations(x, constantTerm(big.NewInt(int64(1))), big.NewInt(int64(-1))), nil)
+}
+
+// lowerAssertBool lowers assert_bool(x), which constrains x to be 0 or 1
+func (b *builder) lowerAssertBool(call *CallExpr) error {
+	if len(call.Args) != 1 {
+		return b.errorf(call.Pos, "assert_bool expects 1 argument")
+	}
+	v, err := b.lowerExpr(call.Args[0], "assert_bool")
+	if err != nil {
+		return err
+	}
+	b.assertBool(b.linear(v, "assert_bool"))
+	return nil
+}
+
+// lowerNum2Bits lowers bits = num2bits(x, n), which assigns to bits[0], ..., bits[n-1] the bits of x from the least significant one. Each bit is constrained to be boolean, and their packing sum(2^i * bits[i]) to be x, so x must fit in n bits
+func (b *builder) lowerNum2Bits(call *CallExpr, s *AssignStmt) error {
+	if len(call.Args) != 2 {
+		return b.errorf(call.Pos, "num2bits expects 2 arguments")
+	}
+	v, err := b.lowerExpr(call.Args[0], s.Out)
+	if err != nil {
+		return err
+	}
+	n, err := b.constInt(call.Args[1], "number of bits")
+	if err != nil {
+		return err
+	}
+	if n < 1 || n > maxBits {
+		return b.errorf(call.Args[1].Position(), "the number of bits must be between 1 and "+strconv.Itoa(maxBits))
+	}
+	x := b.linear(v, s.Out)
+	if err = b.declareArray(s.Pos, s.Out, n); err != nil {
+		return err
+	}
+	b.num2bits(x, s.Out, n)
+	return nil
+}
+
+// num2bits adds the constraints of the bit decomposition of x into the n signals of the array bits. The constraint of each bit, which computes its witness, is the boolean constraint bit * (bit - 1) = 0
+func (b *builder) num2bits(x LinearCombination, bits string, n int) {
+	var packing LinearCombination
+	for i := 0; i < n; i++ {
+		bit := elementName(bits, i)
+		c := Constraint{Op: "bit", V2: strconv.Itoa(i), Out: bit, A: x}
+		c.Literal = constraintLiteral(c)
+		b.constraints = append(b.constraints, c)
+		b.assigned[bit] = true
+		b.addSignal(bit)
+		packing = append(packing, Term{Coeff: new(big.Int).Lsh(big.NewInt(int64(1)), uint(i)), Signal: bit})
+	}
+	b.assert(packing, constantTerm(big.NewInt(int64(1))), x)
+}
@@ -1,13 +1,18 @@
 package circuitcompiler
 
 import (
+	"encoding/json"
 	"errors"
 	"math/big"
 	"strconv"
 
+	"github.com/arnaucube/go-snark-study/bn128"
 	"github.com/arnaucube/go-snark-study/r1csqap"
 )
 
+// witnessField is the scalar field of the BN128 curve, over which the witness is computed
+var witnessField, _ = bn128.NewFqR()
+
 // Circuit is the data structure of the compiled circuit
 type Circuit struct {
 	NVars         int
@@ -36,6 +41,45 @@ type Constraint struct {
 
 	PrivateInputs []string // in func declaration case
 	PublicInputs  []string // in func declaration case
+
+	// linear combinations of the constraints generated from nested expressions, used when B is not empty. With the Op "*" the constraint is Out = A * B + C, and with the Op "/" is Out = A / B.
+	// The Op "assert" is the constraint A * B = C, which does not assign a signal, and the Op "bit" assigns to Out the bit V2 of A, with the constraint Out * (Out - 1) = 0.
+	// The Op "inv" assigns to Out the inverse of A, or 0 if A is 0, and does not have a constraint
+	A LinearCombination `json:",omitempty"`
+	B LinearCombination `json:",omitempty"`
+	C LinearCombination `json:",omitempty"`
+}
+
+// Term is a signal of a linear combination multiplied by its coefficient, the constant terms use the signal "one"
+type Term struct {
+	Coeff  *big.Int
+	Signal string
+}
+
+// LinearCombination is the sum of its terms
+type LinearCombination []Term
+
+func (lc LinearCombination) String() string {
+	if len(lc) == 0 {
+		return "0"
+	}
+	var s string
+	for i, t := range lc {
+		coeff := t.Coeff.String()
+		if i > 0 && t.Coeff.Sign() >= 0 {
+			coeff = "+" + coeff
+		}
+		if t.Signal == "one" {
+			s += coeff
+		} else if t.Coeff.Cmp(big.NewInt(int64(1))) == 0 {
+			s += coeff[:len(coeff)-1] + t.Signal
+		} else if t.Coeff.Cmp(big.NewInt(int64(-1))) == 0 {
+			s += "-" + t.Signal
+		} else {
+			s += coeff + "*" + t.Signal
+		}
+	}
+	return s
 }
 
 func indexInArray(arr []string, e string) int {
@@ -46,18 +90,28 @@ func indexInArray(arr []string, e string) int {
 	}
 	return -1
 }
-func isValue(a string) (bool, int) {
-	v, err := strconv.Atoi(a)
-	if err != nil {
-		return false, 0
+func addToArrayIfNotExist(arr []string, elem string) []string {
+	for _, v := range arr {
+		if v == elem {
+			return arr
+		}
+	}
+	arr = append(arr, elem)
+	return arr
+}
+
+// isValue returns if the string is a constant and its value reduced to the field
+func isValue(a string) (bool, *big.Int) {
+	v, ok := new(big.Int).SetString(a, 10)
+	if !ok {
+		return false, nil
 	}
-	return true, v
+	return true, witnessField.Affine(v)
 }
 func insertVar(arr []*big.Int, signals []string, v string, used map[string]bool) ([]*big.Int, map[string]bool) {
 	isVal, value := isValue(v)
-	valueBigInt := big.NewInt(int64(value))
 	if isVal {
-		arr[0] = new(big.Int).Add(arr[0], valueBigInt)
+		arr[0] = new(big.Int).Add(arr[0], value)
 	} else {
 		if !used[v] {
 			panic(errors.New("using variable before it's set"))
@@ -68,9 +122,8 @@ func insertVar(arr []*big.Int, signals []string, v string, used map[string]bool)
 }
 func insertVarNeg(arr []*big.Int, signals []string, v string, used map[string]bool) ([]*big.Int, map[string]bool) {
 	isVal, value := isValue(v)
-	valueBigInt := big.NewInt(int64(value))
 	if isVal {
-		arr[0] = new(big.Int).Add(arr[0], valueBigInt)
+		arr[0] = new(big.Int).Sub(arr[0], value)
 	} else {
 		if !used[v] {
 			panic(errors.New("using variable before it's set"))
@@ -80,6 +133,17 @@ func insertVarNeg(arr []*big.Int, signals []string, v string, used map[string]bo
 	return arr, used
 }
 
+func insertLinearCombination(arr []*big.Int, signals []string, lc LinearCombination, used map[string]bool) []*big.Int {
+	for _, t := range lc {
+		if t.Signal != "one" && !used[t.Signal] {
+			panic(errors.New("using variable before it's set"))
+		}
+		i := indexInArray(signals, t.Signal)
+		arr[i] = new(big.Int).Add(arr[i], t.Coeff)
+	}
+	return arr
+}
+
 // GenerateR1CS generates the R1CS polynomials from the Circuit
 func (circ *Circuit) GenerateR1CS() ([][]*big.Int, [][]*big.Int, [][]*big.Int) {
 	// from flat code to R1CS
@@ -99,7 +163,34 @@ func (circ *Circuit) GenerateR1CS() ([][]*big.Int, [][]*big.Int, [][]*big.Int) {
 		// panic(errors.New("out variable already used: " + constraint.Out))
 		// }
 		used[constraint.Out] = true
-		if constraint.Op == "in" {
+		if constraint.Op == "inv" {
+			continue
+		}
+		if constraint.Op == "assert" {
+			aConstraint = insertLinearCombination(aConstraint, circ.Signals, constraint.A, used)
+			bConstraint = insertLinearCombination(bConstraint, circ.Signals, constraint.B, used)
+			cConstraint = insertLinearCombination(cConstraint, circ.Signals, constraint.C, used)
+		} else if constraint.Op == "bit" {
+			// Out * (Out - 1) = 0
+			out := indexInArray(circ.Signals, constraint.Out)
+			aConstraint[out] = big.NewInt(int64(1))
+			bConstraint[out] = big.NewInt(int64(1))
+			bConstraint[0] = big.NewInt(int64(-1))
+		} else if len(constraint.B) > 0 {
+			// linear combinations constraint
+			out := indexInArray(circ.Signals, constraint.Out)
+			bConstraint = insertLinearCombination(bConstraint, circ.Signals, constraint.B, used)
+			if constraint.Op == "/" {
+				// Out * B = A
+				aConstraint[out] = big.NewInt(int64(1))
+				cConstraint = insertLinearCombination(cConstraint, circ.Signals, constraint.A, used)
+			} else {
+				// A * B = Out - C
+				aConstraint = insertLinearCombination(aConstraint, circ.Signals, constraint.A, used)
+				cConstraint = insertLinearCombination(cConstraint, circ.Signals, negLinearCombination(constraint.C), used)
+				cConstraint[out] = new(big.Int).Add(cConstraint[out], big.NewInt(int64(1)))
+			}
+		} else if constraint.Op == "in" {
 			for i := 0; i <= len(circ.PublicInputs); i++ {
 				aConstraint[indexInArray(circ.Signals, constraint.Out)] = new(big.Int).Add(aConstraint[indexInArray(circ.Signals, constraint.Out)], big.NewInt(int64(1)))
 				aConstraint, used = insertVar(aConstraint, circ.Signals, constraint.Out, used)
@@ -114,7 +205,7 @@ func (circ *Circuit) GenerateR1CS() ([][]*big.Int, [][]*big.Int, [][]*big.Int) {
 			bConstraint[0] = big.NewInt(int64(1))
 		} else if constraint.Op == "-" {
 			cConstraint[indexInArray(circ.Signals, constraint.Out)] = big.NewInt(int64(1))
-			aConstraint, used = insertVarNeg(aConstraint, circ.Signals, constraint.V1, used)
+			aConstraint, used = insertVar(aConstraint, circ.Signals, constraint.V1, used)
 			aConstraint, used = insertVarNeg(aConstraint, circ.Signals, constraint.V2, used)
 			bConstraint[0] = big.NewInt(int64(1))
 		} else if constraint.Op == "*" {
@@ -123,7 +214,7 @@ func (circ *Circuit) GenerateR1CS() ([][]*big.Int, [][]*big.Int, [][]*big.Int) {
 			bConstraint, used = insertVar(bConstraint, circ.Signals, constraint.V2, used)
 		} else if constraint.Op == "/" {
 			cConstraint, used = insertVar(cConstraint, circ.Signals, constraint.V1, used)
-			cConstraint[indexInArray(circ.Signals, constraint.Out)] = big.NewInt(int64(1))
+			aConstraint[indexInArray(circ.Signals, constraint.Out)] = big.NewInt(int64(1))
 			bConstraint, used = insertVar(bConstraint, circ.Signals, constraint.V2, used)
 		}
 
@@ -140,20 +231,64 @@ func (circ *Circuit) GenerateR1CS() ([][]*big.Int, [][]*big.Int, [][]*big.Int) {
 
 func grabVar(signals []string, w []*big.Int, vStr string) *big.Int {
 	isVal, v := isValue(vStr)
-	vBig := big.NewInt(int64(v))
 	if isVal {
-		return vBig
+		return v
 	} else {
 		return w[indexInArray(signals, vStr)]
 	}
 }
 
 type Inputs struct {
-	Private []*big.Int
-	Public  []*big.Int
+	Private InputValues
+	Public  InputValues
+}
+
+// InputValues are the values of the inputs of a circuit, in the order of the signals. In JSON the values of an array input are a nested array, which is flattened
+type InputValues []*big.Int
+
+// UnmarshalJSON decodes the values of a JSON array, flattening the nested arrays
+func (v *InputValues) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	values := InputValues{}
+	for _, element := range elements {
+		if len(element) > 0 && element[0] == '[' {
+			var array InputValues
+			if err := json.Unmarshal(element, &array); err != nil {
+				return err
+			}
+			values = append(values, array...)
+			continue
+		}
+		n := new(big.Int)
+		if err := json.Unmarshal(element, n); err != nil {
+			return err
+		}
+		values = append(values, n)
+	}
+	*v = values
+	return nil
 }
 
-// CalculateWitness calculates the Witness of a Circuit based on the given inputs
+func negLinearCombination(lc LinearCombination) LinearCombination {
+	var neg LinearCombination
+	for _, t := range lc {
+		neg = append(neg, Term{Coeff: new(big.Int).Neg(t.Coeff), Signal: t.Signal})
+	}
+	return neg
+}
+
+func evalLinearCombination(signals []string, w []*big.Int, lc LinearCombination) *big.Int {
+	r := witnessField.Zero()
+	for _, t := range lc {
+		r = witnessField.Add(r, witnessField.Mul(witnessField.Affine(t.Coeff), w[indexInArray(signals, t.Signal)]))
+	}
+	return r
+}
+
+// CalculateWitness calculates the Witness of a Circuit based on the given inputs, over the scalar field of the BN128 curve
 // witness = [ one, output, publicInputs, privateInputs, ...]
 func (circ *Circuit) CalculateWitness(privateInputs []*big.Int, publicInputs []*big.Int) ([]*big.Int, error) {
 	if len(privateInputs) != len(circ.PrivateInputs) {
@@ -165,22 +300,62 @@ func (circ *Circuit) CalculateWitness(privateInputs []*big.Int, publicInputs []*
 	w := r1csqap.ArrayOfBigZeros(len(circ.Signals))
 	w[0] = big.NewInt(int64(1))
 	for i, input := range publicInputs {
-		w[i+1] = input
+		w[i+1] = witnessField.Affine(input)
 	}
 	for i, input := range privateInputs {
-		w[i+len(publicInputs)+1] = input
+		w[i+len(publicInputs)+1] = witnessField.Affine(input)
 	}
 	for _, constraint := range circ.Constraints {
 		if constraint.Op == "in" {
-		} else if constraint.Op == "+" {
-			w[indexInArray(circ.Signals, constraint.Out)] = new(big.Int).Add(grabVar(circ.Signals, w, constraint.V1), grabVar(circ.Signals, w, constraint.V2))
-		} else if constraint.Op == "-" {
-			w[indexInArray(circ.Signals, constraint.Out)] = new(big.Int).Sub(grabVar(circ.Signals, w, constraint.V1), grabVar(circ.Signals, w, constraint.V2))
-		} else if constraint.Op == "*" {
-			w[indexInArray(circ.Signals, constraint.Out)] = new(big.Int).Mul(grabVar(circ.Signals, w, constraint.V1), grabVar(circ.Signals, w, constraint.V2))
-		} else if constraint.Op == "/" {
-			w[indexInArray(circ.Signals, constraint.Out)] = new(big.Int).Div(grabVar(circ.Signals, w, constraint.V1), grabVar(circ.Signals, w, constraint.V2))
+			continue
+		}
+		if constraint.Op == "inv" {
+			x := evalLinearCombination(circ.Signals, w, constraint.A)
+			if !witnessField.IsZero(x) {
+				x = witnessField.Inverse(x)
+			}
+			w[indexInArray(circ.Signals, constraint.Out)] = x
+			continue
+		}
+		if constraint.Op == "bit" {
+			i, _ := strconv.Atoi(constraint.V2)
+			x := evalLinearCombination(circ.Signals, w, constraint.A)
+			w[indexInArray(circ.Signals, constraint.Out)] = big.NewInt(int64(x.Bit(i)))
+			continue
+		}
+		var a, b *big.Int
+		if len(constraint.B) > 0 || constraint.Op == "assert" {
+			a = evalLinearCombination(circ.Signals, w, constraint.A)
+			b = evalLinearCombination(circ.Signals, w, constraint.B)
+		} else {
+			a = grabVar(circ.Signals, w, constraint.V1)
+			b = grabVar(circ.Signals, w, constraint.V2)
+		}
+		var out *big.Int
+		switch constraint.Op {
+		case "+":
+			out = witnessField.Add(a, b)
+		case "-":
+			out = witnessField.Sub(a, b)
+		case "*":
+			out = witnessField.Mul(a, b)
+			if len(constraint.B) > 0 {
+				out = witnessField.Add(out, evalLinearCombination(circ.Signals, w, constraint.C))
+			}
+		case "assert":
+			if !witnessField.Equal(witnessField.Mul(a, b), evalLinearCombination(circ.Signals, w, constraint.C)) {
+				return nil, errors.New("assertion failed in the constraint " + constraint.Literal)
+			}
+			continue
+		case "/":
+			if witnessField.IsZero(b) {
+				return nil, errors.New("division by zero in the constraint " + constraint.Literal)
+			}
+			out = witnessField.Div(a, b)
+		default:
+			continue
 		}
+		w[indexInArray(circ.Signals, constraint.Out)] = out
 	}
 	return w, nil
 }
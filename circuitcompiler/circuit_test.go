@@ -2,6 +2,7 @@ package circuitcompiler
 
 import (
 	"bufio"
+	"encoding/json"
 	"math/big"
 	"os"
 	"strings"
@@ -36,13 +37,13 @@ func TestCircuitParser(t *testing.T) {
 	b0 := big.NewInt(int64(0))
 	b1 := big.NewInt(int64(1))
 	b5 := big.NewInt(int64(5))
+	bm1 := big.NewInt(int64(-1))
 	aExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b1, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b1, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b1, b0, b1, b0, b0, b0},
 		[]*big.Int{b5, b0, b0, b0, b0, b1, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
+		[]*big.Int{b0, b1, b0, b0, b0, b0, bm1, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	bExpected := [][]*big.Int{
@@ -52,15 +53,13 @@ func TestCircuitParser(t *testing.T) {
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	cExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b0, b1, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b1, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b1, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
+		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b1},
 	}
 
@@ -116,19 +115,19 @@ func TestCircuitWithFuncCallsParser(t *testing.T) {
 	assert.Equal(t, "s0", circuit.PrivateInputs[0])
 	assert.Equal(t, "s1", circuit.PublicInputs[0])
 
-	assert.Equal(t, []string{"one", "s1", "s0", "b0", "s3", "s4", "s5", "out"}, circuit.Signals)
+	assert.Equal(t, []string{"one", "s1", "s0", "exp3.0.b", "s3", "s4", "s5", "out"}, circuit.Signals)
 
 	// expected result
 	b0 := big.NewInt(int64(0))
 	b1 := big.NewInt(int64(1))
 	b5 := big.NewInt(int64(5))
+	bm1 := big.NewInt(int64(-1))
 	aExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b1, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b1, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b1, b0, b1, b0, b0, b0},
 		[]*big.Int{b5, b0, b0, b0, b0, b1, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
+		[]*big.Int{b0, b1, b0, b0, b0, b0, bm1, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	bExpected := [][]*big.Int{
@@ -138,15 +137,13 @@ func TestCircuitWithFuncCallsParser(t *testing.T) {
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	cExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b0, b1, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b1, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b1, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
+		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b1},
 	}
 
@@ -188,19 +185,19 @@ func TestCircuitFromFileWithImports(t *testing.T) {
 	assert.Equal(t, "s0", circuit.PrivateInputs[0])
 	assert.Equal(t, "s1", circuit.PublicInputs[0])
 
-	assert.Equal(t, []string{"one", "s1", "s0", "b0", "s3", "s4", "s5", "out"}, circuit.Signals)
+	assert.Equal(t, []string{"one", "s1", "s0", "exp3.0.b", "s3", "s4", "s5", "out"}, circuit.Signals)
 
 	// expected result
 	b0 := big.NewInt(int64(0))
 	b1 := big.NewInt(int64(1))
 	b5 := big.NewInt(int64(5))
+	bm1 := big.NewInt(int64(-1))
 	aExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b1, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b1, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b1, b0, b1, b0, b0, b0},
 		[]*big.Int{b5, b0, b0, b0, b0, b1, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
+		[]*big.Int{b0, b1, b0, b0, b0, b0, bm1, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	bExpected := [][]*big.Int{
@@ -210,15 +207,13 @@ func TestCircuitFromFileWithImports(t *testing.T) {
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b1, b0, b0, b0, b0, b0, b0, b0},
 	}
 	cExpected := [][]*big.Int{
 		[]*big.Int{b0, b0, b0, b1, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b1, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b1, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
-		[]*big.Int{b0, b1, b0, b0, b0, b0, b0, b0},
-		[]*big.Int{b0, b0, b0, b0, b0, b0, b1, b0},
+		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b0},
 		[]*big.Int{b0, b0, b0, b0, b0, b0, b0, b1},
 	}
 
@@ -246,3 +241,422 @@ func TestCircuitFromFileWithImports(t *testing.T) {
 	assert.Equal(t, len(circuit.PublicInputs), 1)
 	assert.Equal(t, len(circuit.PrivateInputs), 1)
 }
+
+// checkR1CS checks that the witness satisfies the R1CS of the circuit
+func checkR1CS(t *testing.T, circuit *Circuit, w []*big.Int) {
+	dot := func(row []*big.Int) *big.Int {
+		r := big.NewInt(int64(0))
+		for i, v := range row {
+			r.Add(r, new(big.Int).Mul(v, w[i]))
+		}
+		return r.Mod(r, r1csPrime)
+	}
+	for i := range circuit.R1CS.A {
+		ab := new(big.Int).Mul(dot(circuit.R1CS.A[i]), dot(circuit.R1CS.B[i]))
+		ab.Mod(ab, r1csPrime)
+		assert.Equal(t, 0, ab.Cmp(dot(circuit.R1CS.C[i])), "R1CS row %d", i)
+	}
+}
+
+func TestCircuitExpressions(t *testing.T) {
+	// y = x^3 + x + 5
+	code := `
+	func main(private x, public y):
+		z = x*x*x + x + 5
+		equals(y, z)
+		out = 1 * 1
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "y", "x", "z.0", "z", "out"}, circuit.Signals)
+	assert.Equal(t, 6, len(circuit.Constraints))
+	assert.Equal(t, "z.0=(x)*(x)", circuit.Constraints[2].Literal)
+	assert.Equal(t, "z=(z.0)*(x)+x+5", circuit.Constraints[3].Literal)
+	assert.Equal(t, "(y-z)*(1)=0", circuit.Constraints[4].Literal)
+
+	circuit.GenerateR1CS()
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(35))})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[4].Cmp(big.NewInt(int64(35))))
+	checkR1CS(t, circuit, w)
+
+	code = `
+	func sq(private v):
+		return v * v
+
+	func main(private a, private b, public c):
+		d = (a + b) / (a - b)
+		e = -2 * sq(a - b) + 3 * (b - 1) - d
+		f = (a + b) * (a - b) * 2 - (e - 1) * (d + b)
+		g = a - b
+		equals(c, f + g)
+	`
+	circuit, err = NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	circuit.GenerateR1CS()
+	a, b := int64(7), int64(5)
+	d := (a + b) / (a - b)
+	e := -2*(a-b)*(a-b) + 3*(b-1) - d
+	f := (a+b)*(a-b)*2 - (e-1)*(d+b)
+	c := f + a - b
+	w, err = circuit.CalculateWitness([]*big.Int{big.NewInt(a), big.NewInt(b)}, []*big.Int{big.NewInt(c)})
+	assert.Nil(t, err)
+	for signal, expected := range map[string]int64{"d": d, "e": e, "f": f, "g": a - b} {
+		assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(witnessField.Affine(big.NewInt(expected))), signal)
+	}
+	checkR1CS(t, circuit, w)
+}
+
+func TestCircuitEqualsExpression(t *testing.T) {
+	code := `
+	func main(private x, public y):
+		equals(y, 2 * x + 1)
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "y", "x"}, circuit.Signals)
+	assert.Equal(t, "(y-2*x-1)*(1)=0", circuit.Constraints[2].Literal)
+	a, _, _ := circuit.GenerateR1CS()
+
+	// the public input is in the A matrix, so the verifier checks it
+	inA := false
+	for _, row := range a {
+		inA = inA || row[indexInArray(circuit.Signals, "y")].Sign() != 0
+	}
+	assert.True(t, inA)
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(7))})
+	assert.Nil(t, err)
+	checkR1CS(t, circuit, w)
+
+	// the inputs are checked instead of replaced by the value of the expression
+	_, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(8))})
+	assert.EqualError(t, err, "assertion failed in the constraint (y-2*x-1)*(1)=0")
+}
+
+func TestCircuitExp(t *testing.T) {
+	code := `
+	func main(private x, public y):
+		z = x ^ 5 + 2 ^ 3 * x ^ 1 + (x + 1) ^ 0
+		equals(y, z)
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	// x^5 with square-and-multiply: x^2, x^4 and the product x^4 * x folded into z
+	assert.Equal(t, []string{"z.0=(x)*(x)", "z.1=(z.0)*(z.0)", "z=(z.1)*(x)+8*x+1"}, []string{
+		circuit.Constraints[2].Literal, circuit.Constraints[3].Literal, circuit.Constraints[4].Literal,
+	})
+
+	circuit.GenerateR1CS()
+	// 3^5 + 8*3 + 1
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(268))})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "z")].Cmp(big.NewInt(int64(268))))
+	checkR1CS(t, circuit, w)
+
+	// x^11 = ((x^2)^2 * x)^2 * x, with 5 constraints
+	code = `
+	func main(private x):
+		y = x ^ 11
+	`
+	circuit, err = NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(circuit.Constraints))
+	circuit.GenerateR1CS()
+	w, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "y")].Cmp(big.NewInt(int64(177147))))
+	checkR1CS(t, circuit, w)
+}
+
+func TestCalculateWitnessField(t *testing.T) {
+	code := `
+	func main(private a, private b):
+		c = a / b
+		d = a - b
+		e = (a + 1) / 3
+		f = d * d
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	// the division by a constant does not need a constraint
+	assert.Equal(t, 6, len(circuit.Constraints))
+	circuit.GenerateR1CS()
+
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(1)), big.NewInt(int64(3))}, []*big.Int{})
+	assert.Nil(t, err)
+	c := w[indexInArray(circuit.Signals, "c")]
+	assert.Equal(t, 0, witnessField.Mul(c, big.NewInt(int64(3))).Cmp(big.NewInt(int64(1))))
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "d")].Cmp(new(big.Int).Sub(r1csPrime, big.NewInt(int64(2)))))
+	e := w[indexInArray(circuit.Signals, "e")]
+	assert.Equal(t, 0, witnessField.Mul(e, big.NewInt(int64(3))).Cmp(big.NewInt(int64(2))))
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "f")].Cmp(big.NewInt(int64(4))))
+	for _, v := range w {
+		assert.True(t, v.Sign() >= 0 && v.Cmp(r1csPrime) < 0)
+	}
+	checkR1CS(t, circuit, w)
+
+	// negative inputs are reduced to the field
+	w, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(-1)), big.NewInt(int64(1))}, []*big.Int{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "a")].Cmp(new(big.Int).Sub(r1csPrime, big.NewInt(int64(1)))))
+	checkR1CS(t, circuit, w)
+
+	_, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(1)), big.NewInt(int64(0))}, []*big.Int{})
+	assert.Equal(t, "division by zero in the constraint c=a/b", err.Error())
+}
+
+func TestCircuitBigConstants(t *testing.T) {
+	// 2^70 + 1 does not fit in an int
+	code := `
+	func main(private a):
+		b = a * 1180591620717411303425
+		c = a + 1180591620717411303425
+		d = a / 1180591620717411303425
+		e = (a + 1) * 1180591620717411303425
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "a", "b", "c", "d", "e"}, circuit.Signals)
+	circuit.GenerateR1CS()
+
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(2))}, []*big.Int{})
+	assert.Nil(t, err)
+	k, _ := new(big.Int).SetString("1180591620717411303425", 10)
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "b")].Cmp(new(big.Int).Mul(k, big.NewInt(int64(2)))))
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "c")].Cmp(new(big.Int).Add(k, big.NewInt(int64(2)))))
+	assert.Equal(t, 0, witnessField.Mul(w[indexInArray(circuit.Signals, "d")], k).Cmp(big.NewInt(int64(2))))
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "e")].Cmp(new(big.Int).Mul(k, big.NewInt(int64(3)))))
+	checkR1CS(t, circuit, w)
+}
+
+func TestCircuitBits(t *testing.T) {
+	code := `
+	func lsb(private v):
+		bits = num2bits(v, 4)
+		return bits[0]
+
+	func main(private x, private b):
+		assert_bool(b)
+		bits = num2bits(x, 4)
+		y = bits[0] + 2 * bits[3] + b
+		z = lsb(x + 1)
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "x", "b", "bits[0]", "bits[1]", "bits[2]", "bits[3]", "y", "z.0", "z", "lsb.0.bits[1]", "lsb.0.bits[2]", "lsb.0.bits[3]"}, circuit.Signals)
+	assert.Equal(t, "(b)*(b-1)=0", circuit.Constraints[2].Literal)
+	assert.Equal(t, "bits[1]=bit 1 of x: (bits[1])*(bits[1]-1)=0", circuit.Constraints[4].Literal)
+	assert.Equal(t, "(bits[0]+2*bits[1]+4*bits[2]+8*bits[3])*(1)=x", circuit.Constraints[7].Literal)
+	circuit.GenerateR1CS()
+
+	// 11 = 0b1011
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(11)), big.NewInt(int64(1))}, []*big.Int{})
+	assert.Nil(t, err)
+	for signal, expected := range map[string]int64{"bits[0]": 1, "bits[1]": 1, "bits[2]": 0, "bits[3]": 1, "y": 4, "z": 0} {
+		assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(big.NewInt(expected)), signal)
+	}
+	checkR1CS(t, circuit, w)
+
+	// x does not fit in 4 bits
+	_, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(16)), big.NewInt(int64(1))}, []*big.Int{})
+	assert.Equal(t, "assertion failed in the constraint (bits[0]+2*bits[1]+4*bits[2]+8*bits[3])*(1)=x", err.Error())
+	// b is not boolean
+	_, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(11)), big.NewInt(int64(2))}, []*big.Int{})
+	assert.Equal(t, "assertion failed in the constraint (b)*(b-1)=0", err.Error())
+}
+
+func TestCircuitComparisons(t *testing.T) {
+	code := `
+	func main(private a, private b):
+		lt = a < b
+		le = a <= b
+		gt = a > b
+		ge = a >= b
+		ne = a != b
+		k = 3 < 2
+	`
+	parser := NewParser(strings.NewReader(code))
+	parser.ComparisonBits = 8
+	circuit, err := parser.Parse()
+	assert.Nil(t, err)
+	circuit.GenerateR1CS()
+
+	testCases := []struct {
+		a, b               int64
+		lt, le, gt, ge, ne int64
+	}{
+		{3, 5, 1, 1, 0, 0, 1},
+		{5, 3, 0, 0, 1, 1, 1},
+		{4, 4, 0, 1, 0, 1, 0},
+		{0, 255, 1, 1, 0, 0, 1},
+	}
+	for _, tc := range testCases {
+		w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(tc.a), big.NewInt(tc.b)}, []*big.Int{})
+		assert.Nil(t, err)
+		for signal, expected := range map[string]int64{"lt": tc.lt, "le": tc.le, "gt": tc.gt, "ge": tc.ge, "ne": tc.ne, "k": 0} {
+			assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(big.NewInt(expected)), signal)
+		}
+		checkR1CS(t, circuit, w)
+	}
+
+	_, err = NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	parser = NewParser(strings.NewReader(code))
+	parser.ComparisonBits = 253
+	_, err = parser.Parse()
+	assert.Equal(t, "the comparison bits must be between 1 and 252", err.Error())
+}
+
+func TestCircuitConditionals(t *testing.T) {
+	code := `
+	func main(private a, private b, private s):
+		m = s ? a : b + 1
+		if a < b {
+			lo = a
+			hi = b
+		} else {
+			lo = b
+			hi = a
+		}
+		d = hi - lo
+		if s {
+			x = a * b
+		} else if a != b {
+			x = a + b
+		} else {
+			x = 0
+		}
+	`
+	parser := NewParser(strings.NewReader(code))
+	parser.ComparisonBits = 8
+	circuit, err := parser.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, "(s)*(s-1)=0", circuit.Constraints[3].Literal)
+	assert.Equal(t, "m=(s)*(a-b-1)+b+1", circuit.Constraints[4].Literal)
+	circuit.GenerateR1CS()
+
+	testCases := []struct {
+		a, b, s int64
+		m, d, x int64
+	}{
+		{3, 5, 1, 3, 2, 15},
+		{7, 2, 0, 3, 5, 9},
+		{4, 4, 0, 5, 0, 0},
+	}
+	for _, tc := range testCases {
+		w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(tc.a), big.NewInt(tc.b), big.NewInt(tc.s)}, []*big.Int{})
+		assert.Nil(t, err)
+		for signal, expected := range map[string]int64{"m": tc.m, "d": tc.d, "x": tc.x} {
+			assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(big.NewInt(expected)), signal)
+		}
+		checkR1CS(t, circuit, w)
+	}
+
+	// the condition is not boolean
+	_, err = circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3)), big.NewInt(int64(5)), big.NewInt(int64(2))}, []*big.Int{})
+	assert.Equal(t, "assertion failed in the constraint (s)*(s-1)=0", err.Error())
+}
+
+func TestCircuitLoops(t *testing.T) {
+	code := `
+	func main(private x, private k):
+		bits = num2bits(x, 4)
+		for i in 0..4:
+			w = bits[i] * 2^i
+			s = i < 1 ? w : s[i-1] + w
+		// rounds r[i] = (r[i-1] + k)^3
+		for i in 0..3:
+			r = (i < 1 ? x : r[i-1]) + k
+			c = r^3
+			if bits[i] {
+				m = c
+			} else {
+				m = r
+			}
+		for i in 1..3:
+			for j in 0..i:
+				p = i * j + bits[j]
+		y = s[3] + m[2] + p[2][1]
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, "s[1]=s[0]+w[1]", circuit.Constraints[10].Literal)
+	assert.Equal(t, "m[0]=(bits[0])*(m.0-m.1)+m.1", circuit.Constraints[21].Literal)
+	circuit.GenerateR1CS()
+
+	// 11 = 0b1011
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(11)), big.NewInt(int64(1))}, []*big.Int{})
+	assert.Nil(t, err)
+	for signal, expected := range map[string]int64{"s[0]": 1, "s[1]": 3, "s[3]": 11, "r[1]": 13, "m[0]": 1728, "m[1]": 2197, "m[2]": 14, "p[1][0]": 1, "p[2][1]": 3, "y": 28} {
+		assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(big.NewInt(expected)), signal)
+	}
+	checkR1CS(t, circuit, w)
+}
+
+func TestCircuitArrays(t *testing.T) {
+	code := `
+	func sum(private v[3]):
+		for i in 0..3:
+			s = i < 1 ? v[0] : s[i-1] + v[i]
+		return s[2]
+
+	func double(private v[3]):
+		for i in 0..3:
+			d = 2 * v[i]
+		return d
+
+	func id(private v[3]):
+		return v
+
+	func main(private x[3], public k, public y[2]):
+		a = sum(x)
+		d = double(x)
+		e = id(d)
+		b = sum(e) + y[1] * k
+		for i in 0..2:
+			p = double(x)
+		c = sum(p[1])
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x[0]", "x[1]", "x[2]"}, circuit.PrivateInputs)
+	assert.Equal(t, []string{"k", "y[0]", "y[1]"}, circuit.PublicInputs)
+	assert.Equal(t, []string{"one", "k", "y[0]", "y[1]", "x[0]", "x[1]", "x[2]"}, circuit.Signals[:7])
+	circuit.GenerateR1CS()
+
+	var inputs Inputs
+	err = json.Unmarshal([]byte(`{"Private": [[1, 2, 3]], "Public": [10, [4, 5]]}`), &inputs)
+	assert.Nil(t, err)
+	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
+	assert.Nil(t, err)
+	for signal, expected := range map[string]int64{"a": 6, "d[2]": 6, "e[1]": 4, "b": 62, "p[1][2]": 6, "c": 12} {
+		assert.Equal(t, 0, w[indexInArray(circuit.Signals, signal)].Cmp(big.NewInt(expected)), signal)
+	}
+	checkR1CS(t, circuit, w)
+
+	err = json.Unmarshal([]byte(`[1, [2, "a"]]`), &inputs.Private)
+	assert.NotNil(t, err)
+}
+
+func TestCircuitFuncCallSignalNames(t *testing.T) {
+	// the signal t of the call must not overwrite the input t0 of main
+	code := `
+	func square(private a):
+		t = a * a
+		u = t + 0
+		return u
+
+	func main(private t0, private t1):
+		y = square(t1)
+		z = t0 + y
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "t0", "t1", "square.0.t", "y", "z"}, circuit.Signals)
+	circuit.GenerateR1CS()
+
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3)), big.NewInt(int64(5))}, []*big.Int{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[indexInArray(circuit.Signals, "z")].Cmp(big.NewInt(int64(28))))
+	checkR1CS(t, circuit, w)
+}
@@ -0,0 +1,197 @@
+package circuitcompiler
+
+import (
+	"math/big"
+)
+
+// condition returns the linear value of the condition c, constraining it to be boolean. A constant condition must be 0 or 1
+func (b *builder) condition(pos Pos, c value, base string) (value, error) {
+	if k, ok := c.constant(); ok {
+		k = witnessField.Affine(k)
+		if k.Cmp(big.NewInt(int64(1))) > 0 {
+			return value{}, b.errorf(pos, "the condition must be 0 or 1")
+		}
+		return value{lin: constantTerm(k)}, nil
+	}
+	cond := b.linear(c, base)
+	b.assertBool(cond)
+	return value{lin: cond}, nil
+}
+
+// mux returns the value of c ? x : y, being c a condition, which is the product c * (x - y) + y
+func (b *builder) mux(c, x, y value, base string) value {
+	if k, ok := c.constant(); ok {
+		if k.Sign() == 0 {
+			return y
+		}
+		return x
+	}
+	ly := b.linear(y, base)
+	d := addLinearCombinations(b.linear(x, base), ly, big.NewInt(int64(-1)))
+	if k, ok := d.constant(); ok {
+		return value{lin: addLinearCombinations(ly, scaleLinearCombination(c.lin, k), big.NewInt(int64(1)))}
+	}
+	return value{mul: [2]LinearCombination{c.lin, d}, lin: ly}
+}
+
+// lowerCond lowers the expression c ? x : y. If c is a constant, only the selected expression is lowered, as in the loops with a condition on the counter
+func (b *builder) lowerCond(x *CondExpr, base string) (value, error) {
+	v, err := b.lowerExpr(x.Cond, base)
+	if err != nil {
+		return value{}, err
+	}
+	c, err := b.condition(x.Cond.Position(), v, base)
+	if err != nil {
+		return value{}, err
+	}
+	if k, ok := c.constant(); ok {
+		if k.Sign() == 0 {
+			return b.lowerExpr(x.Y, base)
+		}
+		return b.lowerExpr(x.X, base)
+	}
+	vx, err := b.lowerExpr(x.X, base)
+	if err != nil {
+		return value{}, err
+	}
+	vy, err := b.lowerExpr(x.Y, base)
+	if err != nil {
+		return value{}, err
+	}
+	return b.mux(c, vx, vy, base), nil
+}
+
+// lowerIf lowers the if statement. Both blocks are lowered, with their assignments to intermediate signals, and each signal assigned in the blocks is the selection of its values with the condition.
+// names maps the signals assigned in the enclosing blocks to their intermediate signals, being nil out of the blocks, and it gets the intermediate signals of the selections. It returns the signals assigned by the if statement, in order
+func (b *builder) lowerIf(s *IfStmt, names map[string]string) ([]string, error) {
+	c, err := b.lowerExpr(b.substitute(s.Cond, names), "if")
+	if err != nil {
+		return nil, err
+	}
+	if c, err = b.condition(s.Cond.Position(), c, "if"); err != nil {
+		return nil, err
+	}
+	thenNames, thenAssigned, err := b.lowerBlock(s.Then, names)
+	if err != nil {
+		return nil, err
+	}
+	elseNames, elseAssigned, err := b.lowerBlock(s.Else, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, assigned := range [][]string{thenAssigned, elseAssigned} {
+		for _, name := range assigned {
+			if _, ok := thenNames[name]; !ok {
+				return nil, b.errorf(s.Pos, "signal "+name+" is not assigned in the if block")
+			}
+			if _, ok := elseNames[name]; !ok {
+				return nil, b.errorf(s.Pos, "signal "+name+" is not assigned in the else block")
+			}
+		}
+	}
+
+	for _, name := range thenAssigned {
+		out := name
+		if names != nil {
+			out = b.tempName(name)
+			names[name] = out
+		} else if out, err = b.assignTarget(s.Pos, name); err != nil {
+			return nil, err
+		} else {
+			b.bind(name, out)
+		}
+		t, e := thenNames[name], elseNames[name]
+		n, isArray := b.arrays[t]
+		if m, ok := b.arrays[e]; ok != isArray || m != n {
+			return nil, b.errorf(s.Pos, "signal "+name+" has a different length in each block")
+		}
+		if !isArray {
+			if err = b.assign(s.Pos, out); err != nil {
+				return nil, err
+			}
+			b.lowerMux(c, t, e, out)
+			continue
+		}
+		if err = b.declareArray(s.Pos, out, n); err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			element := elementName(out, i)
+			b.assigned[element] = true
+			b.lowerMux(c, elementName(t, i), elementName(e, i), element)
+		}
+	}
+	return thenAssigned, nil
+}
+
+// lowerMux adds the constraint out = c ? t : e, being t and e signals
+func (b *builder) lowerMux(c value, t, e, out string) {
+	b.emitValue(out, b.mux(c, value{lin: signalTerm(t)}, value{lin: signalTerm(e)}, out))
+}
+
+// lowerBlock lowers the statements of a block of an if statement, assigning the signals to intermediate signals. It returns the names map of the block and the signals assigned in the block, in order
+func (b *builder) lowerBlock(stmts []Stmt, names map[string]string) (map[string]string, []string, error) {
+	blockNames := make(map[string]string)
+	for name, s := range names {
+		blockNames[name] = s
+	}
+	var assigned []string
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *AssignStmt:
+			if _, ok := blockNames[s.Out]; ok || b.declared(s.Out) {
+				return nil, nil, b.errorf(s.Pos, "signal "+s.Out+" already assigned")
+			}
+			out := b.tempName(s.Out)
+			if err := b.lowerAssign(&AssignStmt{Pos: s.Pos, Out: out, X: b.substitute(s.X, blockNames)}); err != nil {
+				return nil, nil, err
+			}
+			blockNames[s.Out] = out
+			assigned = append(assigned, s.Out)
+		case *IfStmt:
+			ifAssigned, err := b.lowerIf(s, blockNames)
+			if err != nil {
+				return nil, nil, err
+			}
+			assigned = append(assigned, ifAssigned...)
+		default:
+			return nil, nil, b.errorf(stmt.Position(), "only assignments are allowed inside an if block")
+		}
+	}
+	return blockNames, assigned, nil
+}
+
+// substitute returns the expression x with its signals renamed with the names map. The arrays are renamed only if they are assigned in the block, as the other names can be elements of the arrays of a loop
+func (b *builder) substitute(x Expr, names map[string]string) Expr {
+	if len(names) == 0 {
+		return x
+	}
+	switch x := x.(type) {
+	case *Ident:
+		if s, ok := names[x.Name]; ok {
+			return &Ident{Pos: x.Pos, Name: s}
+		}
+	case *IndexExpr:
+		r := &IndexExpr{Pos: x.Pos, Name: x.Name}
+		for _, index := range x.Indexes {
+			r.Indexes = append(r.Indexes, b.substitute(index, names))
+		}
+		if _, ok := b.arrays[names[x.Name]]; ok {
+			r.Name = names[x.Name]
+		}
+		return r
+	case *BinaryExpr:
+		return &BinaryExpr{Pos: x.Pos, Op: x.Op, X: b.substitute(x.X, names), Y: b.substitute(x.Y, names)}
+	case *UnaryExpr:
+		return &UnaryExpr{Pos: x.Pos, Op: x.Op, X: b.substitute(x.X, names)}
+	case *CondExpr:
+		return &CondExpr{Pos: x.Pos, Cond: b.substitute(x.Cond, names), X: b.substitute(x.X, names), Y: b.substitute(x.Y, names)}
+	case *CallExpr:
+		r := &CallExpr{Pos: x.Pos, Func: x.Func}
+		for _, arg := range x.Args {
+			r.Args = append(r.Args, b.substitute(arg, names))
+		}
+		return r
+	}
+	return x
+}
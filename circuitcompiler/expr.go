@@ -0,0 +1,369 @@
+package circuitcompiler
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// value is the result of lowering an expression, mul[0] * mul[1] + lin. The product is kept pending, so it can be folded into the constraint of the signal where the value is assigned
+type value struct {
+	mul [2]LinearCombination // pending product, nil if there is none
+	lin LinearCombination
+}
+
+func constantTerm(v *big.Int) LinearCombination {
+	if v.Sign() == 0 {
+		return nil
+	}
+	return LinearCombination{{Coeff: v, Signal: "one"}}
+}
+
+func signalTerm(s string) LinearCombination {
+	return LinearCombination{{Coeff: big.NewInt(int64(1)), Signal: s}}
+}
+
+// addLinearCombinations returns x + k*y, merging the terms of the same signal
+func addLinearCombinations(x, y LinearCombination, k *big.Int) LinearCombination {
+	var r LinearCombination
+	for _, t := range x {
+		r = append(r, Term{Coeff: new(big.Int).Set(t.Coeff), Signal: t.Signal})
+	}
+	for _, t := range y {
+		coeff := new(big.Int).Mul(t.Coeff, k)
+		merged := false
+		for i := range r {
+			if r[i].Signal == t.Signal {
+				r[i].Coeff = new(big.Int).Add(r[i].Coeff, coeff)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			r = append(r, Term{Coeff: coeff, Signal: t.Signal})
+		}
+	}
+	// remove the terms that cancelled
+	var nonZero LinearCombination
+	for _, t := range r {
+		if t.Coeff.Sign() != 0 {
+			nonZero = append(nonZero, t)
+		}
+	}
+	return nonZero
+}
+
+func scaleLinearCombination(lc LinearCombination, k *big.Int) LinearCombination {
+	return addLinearCombinations(nil, lc, k)
+}
+
+// constant returns the value of the linear combination if it does not have signals
+func (lc LinearCombination) constant() (*big.Int, bool) {
+	v := big.NewInt(int64(0))
+	for _, t := range lc {
+		if t.Signal != "one" {
+			return nil, false
+		}
+		v = new(big.Int).Add(v, t.Coeff)
+	}
+	return v, true
+}
+
+// signal returns the signal of the linear combination if it is a single signal with coefficient 1
+func (lc LinearCombination) signal() (string, bool) {
+	if len(lc) != 1 || lc[0].Signal == "one" || lc[0].Coeff.Cmp(big.NewInt(int64(1))) != 0 {
+		return "", false
+	}
+	return lc[0].Signal, true
+}
+
+func (v value) constant() (*big.Int, bool) {
+	if v.mul[0] != nil {
+		return nil, false
+	}
+	return v.lin.constant()
+}
+
+// signal returns the signal of the value if it is a single signal
+func (v value) signal() (string, bool) {
+	if v.mul[0] != nil {
+		return "", false
+	}
+	return v.lin.signal()
+}
+
+func (v value) scale(k *big.Int) value {
+	r := value{lin: scaleLinearCombination(v.lin, k)}
+	if v.mul[0] != nil && k.Sign() != 0 {
+		r.mul = [2]LinearCombination{scaleLinearCombination(v.mul[0], k), v.mul[1]}
+	}
+	return r
+}
+
+// fresh returns a new intermediate signal for the expression assigned to base. The names have a dot, so they can not collide with the signals of the code
+func (b *builder) fresh(base string) string {
+	s := b.tempName(base)
+	b.assigned[s] = true
+	return s
+}
+
+// tempName returns a new intermediate signal of base, which is not marked as assigned, as the signals assigned inside an if block
+func (b *builder) tempName(base string) string {
+	if b.temps == nil {
+		b.temps = make(map[string]int)
+	}
+	s := base + "." + strconv.Itoa(b.temps[base])
+	b.temps[base]++
+	return s
+}
+
+// constraintLiteral returns the literal of a constraint
+func constraintLiteral(c Constraint) string {
+	switch {
+	case c.Op == "assert":
+		return "(" + c.A.String() + ")*(" + c.B.String() + ")=" + c.C.String()
+	case c.Op == "inv":
+		return c.Out + "=inverse of " + c.A.String() + " or 0"
+	case c.Op == "bit":
+		return c.Out + "=bit " + c.V2 + " of " + c.A.String() + ": (" + c.Out + ")*(" + c.Out + "-1)=0"
+	case len(c.B) == 0:
+		return c.Out + "=" + c.V1 + c.Op + c.V2
+	case c.Op == "/":
+		return c.Out + "=(" + c.A.String() + ")/(" + c.B.String() + ")"
+	}
+	if k, ok := c.B.constant(); ok && k.Cmp(big.NewInt(int64(1))) == 0 && len(c.C) == 0 {
+		return c.Out + "=" + c.A.String()
+	}
+	l := c.Out + "=(" + c.A.String() + ")*(" + c.B.String() + ")"
+	if cs := c.C.String(); len(c.C) > 0 && cs[0] == '-' {
+		l += cs
+	} else if len(c.C) > 0 {
+		l += "+" + cs
+	}
+	return l
+}
+
+// emitValue adds the constraint out = v, in a single constraint
+func (b *builder) emitValue(out string, v value) {
+	c := Constraint{Op: "*", Out: out}
+	if v.mul[0] != nil {
+		c.A, c.B, c.C = v.mul[0], v.mul[1], v.lin
+	} else {
+		c.A, c.B = v.lin, constantTerm(big.NewInt(int64(1)))
+	}
+	c.Literal = constraintLiteral(c)
+	b.constraints = append(b.constraints, c)
+	b.addSignal(out)
+}
+
+// assert adds the constraint A * B = C, which does not assign any signal
+func (b *builder) assert(a, bb, c LinearCombination) {
+	constraint := Constraint{Op: "assert", A: a, B: bb, C: c}
+	constraint.Literal = constraintLiteral(constraint)
+	b.constraints = append(b.constraints, constraint)
+}
+
+// linear returns the value as a linear combination, assigning its pending product to an intermediate signal
+func (b *builder) linear(v value, base string) LinearCombination {
+	if v.mul[0] == nil {
+		return v.lin
+	}
+	t := b.fresh(base)
+	b.emitValue(t, v)
+	return signalTerm(t)
+}
+
+// signalOf returns the value as a signal or a constant, assigning it to an intermediate signal if it is not
+func (b *builder) signalOf(v value, base string) string {
+	if k, ok := v.constant(); ok {
+		return k.String()
+	}
+	if s, ok := v.signal(); ok {
+		return s
+	}
+	t := b.fresh(base)
+	b.emitValue(t, v)
+	return t
+}
+
+// lowerExpr lowers the expression into a value, adding the constraints of its products and divisions. base is the assigned signal, used to name the intermediate signals
+func (b *builder) lowerExpr(x Expr, base string) (value, error) {
+	switch x := x.(type) {
+	case *IndexExpr:
+		s, err := b.element(x)
+		if err != nil {
+			return value{}, err
+		}
+		return value{lin: signalTerm(s)}, nil
+	case *Ident:
+		if k, ok := b.consts[x.Name]; ok {
+			return value{lin: constantTerm(k)}, nil
+		}
+		s := b.resolve(x.Name)
+		if _, ok := b.arrays[s]; ok {
+			return value{}, b.errorf(x.Pos, x.Name+" is an array, its elements are used as "+x.Name+"[i]")
+		}
+		if !b.assigned[s] {
+			return value{}, b.errorf(x.Pos, "using signal "+x.Name+" before it's set")
+		}
+		return value{lin: signalTerm(s)}, nil
+	case *Number:
+		return value{lin: constantTerm(x.Value)}, nil
+	case *UnaryExpr:
+		v, err := b.lowerExpr(x.X, base)
+		if err != nil {
+			return value{}, err
+		}
+		return v.scale(big.NewInt(int64(-1))), nil
+	case *CallExpr:
+		t := b.tempName(base)
+		isArray, err := b.lowerCall(x, t)
+		if err != nil {
+			return value{}, err
+		}
+		if isArray {
+			return value{}, b.errorf(x.Pos, "the result of "+x.Func+" is an array, it must be assigned")
+		}
+		b.assigned[t] = true
+		return value{lin: signalTerm(t)}, nil
+	case *CondExpr:
+		return b.lowerCond(x, base)
+	case *BinaryExpr:
+		vx, err := b.lowerExpr(x.X, base)
+		if err != nil {
+			return value{}, err
+		}
+		vy, err := b.lowerExpr(x.Y, base)
+		if err != nil {
+			return value{}, err
+		}
+		return b.lowerBinary(x, vx, vy, base)
+	}
+	return value{}, b.errorf(x.Position(), "unsupported expression")
+}
+
+func (b *builder) lowerBinary(x *BinaryExpr, vx, vy value, base string) (value, error) {
+	switch x.Op {
+	case PLUS, MINUS:
+		k := big.NewInt(int64(1))
+		if x.Op == MINUS {
+			k = big.NewInt(int64(-1))
+		}
+		if vx.mul[0] != nil && vy.mul[0] != nil {
+			// only one product can be folded
+			vy = value{lin: b.linear(vy, base)}
+		}
+		r := value{mul: vx.mul, lin: addLinearCombinations(vx.lin, vy.lin, k)}
+		if vy.mul[0] != nil {
+			r.mul = [2]LinearCombination{scaleLinearCombination(vy.mul[0], k), vy.mul[1]}
+		}
+		return r, nil
+	case MULTIPLY:
+		if k, ok := vx.constant(); ok {
+			return vy.scale(k), nil
+		}
+		if k, ok := vy.constant(); ok {
+			return vx.scale(k), nil
+		}
+		return value{mul: [2]LinearCombination{b.linear(vx, base), b.linear(vy, base)}}, nil
+	case LT, LE, GT, GE:
+		return b.lowerComparison(x.Op, vx, vy, base), nil
+	case NEQ:
+		return b.lowerNotEqual(vx, vy, base), nil
+	case EXP:
+		e, ok := vy.constant()
+		if !ok || e.Sign() < 0 {
+			return value{}, b.errorf(x.Pos, "the exponent must be a non negative constant")
+		}
+		if k, ok := vx.constant(); ok {
+			return value{lin: constantTerm(witnessField.Exp(witnessField.Affine(k), e))}, nil
+		}
+		return b.lowerExp(vx, e, base), nil
+	case DIVIDE:
+		if k, ok := vy.constant(); ok {
+			if k.Sign() == 0 {
+				return value{}, b.errorf(x.Pos, "division by zero")
+			}
+			if k.Cmp(big.NewInt(int64(1))) == 0 {
+				return vx, nil
+			}
+			// the division by a constant is the product by its inverse in the field
+			return vx.scale(witnessField.Inverse(witnessField.Affine(k))), nil
+		}
+		c := Constraint{Op: "/", A: b.linear(vx, base), B: b.linear(vy, base)}
+		c.Out = b.fresh(base)
+		c.Literal = constraintLiteral(c)
+		b.constraints = append(b.constraints, c)
+		b.addSignal(c.Out)
+		return value{lin: signalTerm(c.Out)}, nil
+	}
+	return value{}, b.errorf(x.Pos, "unsupported operator "+x.Op.String())
+}
+
+// lowerExp lowers v^e by square-and-multiply, from the most significant bit of e, so x^5 is x2 = x*x, x4 = x2*x2 and x4*x. The last product is kept pending, to be folded with the rest of the expression
+func (b *builder) lowerExp(v value, e *big.Int, base string) value {
+	if e.Sign() == 0 {
+		return value{lin: constantTerm(big.NewInt(int64(1)))}
+	}
+	x := b.linear(v, base)
+	r := value{lin: x}
+	for i := e.BitLen() - 2; i >= 0; i-- {
+		sq := b.linear(r, base)
+		r = value{mul: [2]LinearCombination{sq, sq}}
+		if e.Bit(i) == 1 {
+			r = value{mul: [2]LinearCombination{b.linear(r, base), x}}
+		}
+	}
+	return r
+}
+
+// lowerComparison lowers the comparisons < <= > >= to the boolean value of a < b, or its negation, with the operands swapped for > and <=.
+// a < b is the negation of the most significant bit of a - b + 2^n, being n the number of bits of the compared values
+func (b *builder) lowerComparison(op Token, va, vb value, base string) value {
+	if op == GT || op == LE {
+		va, vb = vb, va
+	}
+	negate := op == GE || op == LE
+	one := big.NewInt(int64(1))
+	n := b.l.compBits
+
+	if ka, ok := va.constant(); ok {
+		if kb, ok := vb.constant(); ok {
+			lt := witnessField.Affine(ka).Cmp(witnessField.Affine(kb)) < 0
+			if lt != negate {
+				return value{lin: constantTerm(one)}
+			}
+			return value{}
+		}
+	}
+	x := addLinearCombinations(b.linear(va, base), b.linear(vb, base), big.NewInt(int64(-1)))
+	x = addLinearCombinations(x, constantTerm(new(big.Int).Lsh(one, uint(n))), one)
+	bits := b.fresh(base)
+	b.num2bits(x, bits, n+1)
+	msb := signalTerm(elementName(bits, n))
+	if negate {
+		// a >= b is the most significant bit
+		return value{lin: msb}
+	}
+	return value{lin: addLinearCombinations(constantTerm(one), msb, big.NewInt(int64(-1)))}
+}
+
+// lowerNotEqual lowers a != b with the inverse trick: being d = a - b and inv its inverse, or 0 if d is 0, the result is out = d * inv, constrained with d * (1 - out) = 0
+func (b *builder) lowerNotEqual(va, vb value, base string) value {
+	one := big.NewInt(int64(1))
+	d := addLinearCombinations(b.linear(va, base), b.linear(vb, base), big.NewInt(int64(-1)))
+	if k, ok := d.constant(); ok {
+		if witnessField.IsZero(witnessField.Affine(k)) {
+			return value{}
+		}
+		return value{lin: constantTerm(one)}
+	}
+	inv := Constraint{Op: "inv", Out: b.fresh(base), A: d}
+	inv.Literal = constraintLiteral(inv)
+	b.constraints = append(b.constraints, inv)
+	b.addSignal(inv.Out)
+
+	out := b.fresh(base)
+	b.emitValue(out, value{mul: [2]LinearCombination{d, signalTerm(inv.Out)}})
+	b.assert(d, addLinearCombinations(constantTerm(one), signalTerm(out), big.NewInt(int64(-1))), nil)
+	return value{lin: signalTerm(out)}
+}
@@ -4,20 +4,20 @@ import (
 	"bufio"
 	"bytes"
 	"io"
+	"strconv"
 )
 
-type OperatorSymbol int
+// Token is the kind of a lexical token of the circuit language
 type Token int
 
 const (
 	ILLEGAL Token = iota
-	WS
 	EOF
+	NEWLINE
 
-	IDENT // val
-
-	VAR   // var
-	CONST // const value
+	IDENT  // s0
+	CONST  // 5
+	STRING // "file.circuit"
 
 	EQ       // =
 	PLUS     // +
@@ -25,123 +25,324 @@ const (
 	MULTIPLY // *
 	DIVIDE   // /
 	EXP      // ^
+	LT       // <
+	LE       // <=
+	GT       // >
+	GE       // >=
+	NEQ      // !=
+	LPAREN   // (
+	RPAREN   // )
+	LBRACK   // [
+	RBRACK   // ]
+	LBRACE   // {
+	RBRACE   // }
+	COMMA    // ,
+	COLON    // :
+	QUESTION // ?
+	DOTDOT   // ..
 
-	OUT
+	keywordsBegin
+	FUNC    // func
+	PRIVATE // private
+	PUBLIC  // public
+	RETURN  // return
+	IMPORT  // import
+	IF      // if
+	ELSE    // else
+	FOR     // for
+	keywordsEnd
 )
 
+var tokens = [...]string{
+	ILLEGAL: "ILLEGAL",
+	EOF:     "EOF",
+	NEWLINE: "newline",
+	IDENT:   "identifier",
+	CONST:   "constant",
+	STRING:  "string",
+
+	EQ:       "=",
+	PLUS:     "+",
+	MINUS:    "-",
+	MULTIPLY: "*",
+	DIVIDE:   "/",
+	EXP:      "^",
+	LT:       "<",
+	LE:       "<=",
+	GT:       ">",
+	GE:       ">=",
+	NEQ:      "!=",
+	LPAREN:   "(",
+	RPAREN:   ")",
+	LBRACK:   "[",
+	RBRACK:   "]",
+	LBRACE:   "{",
+	RBRACE:   "}",
+	COMMA:    ",",
+	COLON:    ":",
+	QUESTION: "?",
+	DOTDOT:   "..",
+
+	FUNC:    "func",
+	PRIVATE: "private",
+	PUBLIC:  "public",
+	RETURN:  "return",
+	IMPORT:  "import",
+	IF:      "if",
+	ELSE:    "else",
+	FOR:     "for",
+}
+
+func (tok Token) String() string {
+	if tok >= 0 && int(tok) < len(tokens) && tokens[tok] != "" {
+		return tokens[tok]
+	}
+	return "token(" + strconv.Itoa(int(tok)) + ")"
+}
+
+var keywords map[string]Token
+
+func init() {
+	keywords = make(map[string]Token)
+	for tok := keywordsBegin + 1; tok < keywordsEnd; tok++ {
+		keywords[tokens[tok]] = tok
+	}
+}
+
+// Pos is a position in the circuit source code, with the line and column starting at 1
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (pos Pos) String() string {
+	return strconv.Itoa(pos.Line) + ":" + strconv.Itoa(pos.Col)
+}
+
 var eof = rune(0)
 
 func isWhitespace(ch rune) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\v' || ch == '\f'
+	return ch == ' ' || ch == '\t' || ch == '\r' || ch == '\v' || ch == '\f'
 }
 
 func isLetter(ch rune) bool {
-	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_'
 }
 func isDigit(ch rune) bool {
 	return (ch >= '0' && ch <= '9')
 }
 
-// Scanner holds the bufio.Reader
+// Scanner is the tokenizer of the circuit language, it holds the bufio.Reader and the current position
 type Scanner struct {
-	r *bufio.Reader
+	r       *bufio.Reader
+	pos     Pos    // position of the next rune
+	unreads []rune // runes unread, returned again by read in reverse order
+	cols    []int  // columns of the ends of the previous lines, to unread the newlines
+	comment *Pos   // position of a /* comment not closed before the end of the file
 }
 
 // NewScanner creates a new Scanner with the given io.Reader
 func NewScanner(r io.Reader) *Scanner {
-	return &Scanner{r: bufio.NewReader(r)}
+	return &Scanner{r: bufio.NewReader(r), pos: Pos{Line: 1, Col: 1}}
 }
 
 func (s *Scanner) read() rune {
-	ch, _, err := s.r.ReadRune()
-	if err != nil {
-		return eof
+	var ch rune
+	if n := len(s.unreads); n > 0 {
+		ch = s.unreads[n-1]
+		s.unreads = s.unreads[:n-1]
+	} else {
+		var err error
+		ch, _, err = s.r.ReadRune()
+		if err != nil {
+			return eof
+		}
+	}
+	if ch == '\n' {
+		s.cols = append(s.cols, s.pos.Col)
+		s.pos.Line++
+		s.pos.Col = 1
+	} else {
+		s.pos.Col++
 	}
 	return ch
 }
 
-func (s *Scanner) unread() {
-	_ = s.r.UnreadRune()
+func (s *Scanner) unread(ch rune) {
+	if ch == eof {
+		return
+	}
+	s.unreads = append(s.unreads, ch)
+	if ch == '\n' {
+		s.pos.Line--
+		s.pos.Col = s.cols[len(s.cols)-1]
+		s.cols = s.cols[:len(s.cols)-1]
+	} else {
+		s.pos.Col--
+	}
 }
 
-// Scan returns the Token and literal string of the current value
-func (s *Scanner) scan() (tok Token, lit string) {
+func (s *Scanner) peek() rune {
 	ch := s.read()
+	s.unread(ch)
+	return ch
+}
 
-	if isWhitespace(ch) {
-		// space
-		s.unread()
-		return s.scanWhitespace()
-	} else if isLetter(ch) {
-		// letter
-		s.unread()
-		return s.scanIndent()
+// Scan returns the next token, its literal string and its position. The whitespaces and the comments (from // to the end of the line, and between /* and */) are skipped, and the consecutive newlines are returned as a single NEWLINE token
+func (s *Scanner) Scan() (pos Pos, tok Token, lit string) {
+	s.skipWhitespaceAndComments()
+	if s.comment != nil {
+		pos, s.comment = *s.comment, nil
+		return pos, ILLEGAL, "/*"
+	}
+	pos = s.pos
+	ch := s.read()
+
+	if isLetter(ch) {
+		s.unread(ch)
+		lit = s.scanWhile(func(ch rune) bool { return isLetter(ch) || isDigit(ch) })
+		if kw, ok := keywords[lit]; ok {
+			return pos, kw, lit
+		}
+		return pos, IDENT, lit
 	} else if isDigit(ch) {
-		s.unread()
-		return s.scanIndent()
+		s.unread(ch)
+		lit = s.scanWhile(isDigit)
+		if next := s.peek(); isLetter(next) {
+			return pos, ILLEGAL, lit + string(next)
+		}
+		return pos, CONST, lit
 	}
 
 	switch ch {
 	case eof:
-		return EOF, ""
+		return pos, EOF, ""
+	case '\n':
+		for {
+			s.skipWhitespaceAndComments()
+			if next := s.read(); next != '\n' || s.comment != nil {
+				s.unread(next)
+				break
+			}
+		}
+		return pos, NEWLINE, "\n"
+	case '"':
+		return s.scanString(pos)
 	case '=':
-		return EQ, "="
+		return pos, EQ, "="
 	case '+':
-		return PLUS, "+"
+		return pos, PLUS, "+"
 	case '-':
-		return MINUS, "-"
+		return pos, MINUS, "-"
 	case '*':
-		return MULTIPLY, "*"
+		return pos, MULTIPLY, "*"
 	case '/':
-		return DIVIDE, "/"
+		return pos, DIVIDE, "/"
 	case '^':
-		return EXP, "^"
+		return pos, EXP, "^"
+	case '<':
+		if s.peek() == '=' {
+			s.read()
+			return pos, LE, "<="
+		}
+		return pos, LT, "<"
+	case '>':
+		if s.peek() == '=' {
+			s.read()
+			return pos, GE, ">="
+		}
+		return pos, GT, ">"
+	case '!':
+		if s.peek() == '=' {
+			s.read()
+			return pos, NEQ, "!="
+		}
+	case '(':
+		return pos, LPAREN, "("
+	case ')':
+		return pos, RPAREN, ")"
+	case '[':
+		return pos, LBRACK, "["
+	case ']':
+		return pos, RBRACK, "]"
+	case '{':
+		return pos, LBRACE, "{"
+	case '}':
+		return pos, RBRACE, "}"
+	case ',':
+		return pos, COMMA, ","
+	case ':':
+		return pos, COLON, ":"
+	case '?':
+		return pos, QUESTION, "?"
+	case '.':
+		if s.peek() == '.' {
+			s.read()
+			return pos, DOTDOT, ".."
+		}
 	}
-
-	return ILLEGAL, string(ch)
+	return pos, ILLEGAL, string(ch)
 }
 
-func (s *Scanner) scanWhitespace() (token Token, lit string) {
-	var buf bytes.Buffer
-	buf.WriteRune(s.read())
-
+// skipWhitespaceAndComments skips the whitespaces and the comments, but not the newlines ending the line comments. A /* comment not closed before the end of the file is kept in s.comment, to be returned as ILLEGAL
+func (s *Scanner) skipWhitespaceAndComments() {
 	for {
-		if ch := s.read(); ch == eof {
-			break
-		} else if !isWhitespace(ch) {
-			s.unread()
-			break
-		} else {
-			_, _ = buf.WriteRune(ch)
+		start := s.pos
+		ch := s.read()
+		if isWhitespace(ch) {
+			continue
+		}
+		if ch == '/' {
+			next := s.read()
+			if next == '/' {
+				for ch = s.read(); ch != '\n' && ch != eof; ch = s.read() {
+				}
+				s.unread(ch)
+				continue
+			}
+			if next == '*' {
+				prev := eof
+				for ch = s.read(); ch != eof && !(prev == '*' && ch == '/'); ch = s.read() {
+					prev = ch
+				}
+				if ch == eof {
+					s.comment = &start
+					return
+				}
+				continue
+			}
+			s.unread(next)
 		}
+		s.unread(ch)
+		return
 	}
-	return WS, buf.String()
 }
 
-func (s *Scanner) scanIndent() (tok Token, lit string) {
+func (s *Scanner) scanWhile(cond func(rune) bool) string {
 	var buf bytes.Buffer
-	buf.WriteRune(s.read())
-
 	for {
-		if ch := s.read(); ch == eof {
+		ch := s.read()
+		if ch == eof || !cond(ch) {
+			s.unread(ch)
 			break
-		} else if !isLetter(ch) && !isDigit(ch) {
-			s.unread()
-			break
-		} else {
-			_, _ = buf.WriteRune(ch)
 		}
+		buf.WriteRune(ch)
 	}
-	switch buf.String() {
-	case "var":
-		return VAR, buf.String()
-	}
+	return buf.String()
+}
 
-	if len(buf.String()) == 1 {
-		return Token(rune(buf.String()[0])), buf.String()
-	}
-	if buf.String() == "out" {
-		return OUT, buf.String()
+// scanString scans a string until the closing quote, returning ILLEGAL if the line ends before it
+func (s *Scanner) scanString(pos Pos) (Pos, Token, string) {
+	var buf bytes.Buffer
+	for {
+		ch := s.read()
+		if ch == '"' {
+			return pos, STRING, buf.String()
+		}
+		if ch == '\n' || ch == eof {
+			s.unread(ch)
+			return pos, ILLEGAL, "\"" + buf.String()
+		}
+		buf.WriteRune(ch)
 	}
-	return IDENT, buf.String()
 }
@@ -0,0 +1,102 @@
+package circuitcompiler
+
+import (
+	"math/big"
+)
+
+// loop is the state of a for statement being unrolled
+type loop struct {
+	value    int               // value of the counter in the current iteration
+	end      int               // length of the arrays of the signals assigned in the body
+	names    map[string]string // signals assigned in the current iteration, to their element of the array
+	declared map[string]bool   // arrays declared by the loop
+}
+
+// lowerFor unrolls the for statement, lowering its body once for each value of the counter. The signals assigned in the body are arrays, and each iteration assigns their element of the counter value, x[i], which the body uses as x
+func (b *builder) lowerFor(s *ForStmt) error {
+	if _, ok := b.consts[s.Counter]; ok || b.declared(s.Counter) {
+		return b.errorf(s.Pos, s.Counter+" already declared")
+	}
+	from, err := b.constInt(s.From, "loop bound")
+	if err != nil {
+		return err
+	}
+	to, err := b.constInt(s.To, "loop bound")
+	if err != nil {
+		return err
+	}
+	if from < 0 || to < 0 {
+		return b.errorf(s.From.Position(), "the loop bounds must be non negative")
+	}
+	if b.consts == nil {
+		b.consts = make(map[string]*big.Int)
+	}
+	l := &loop{end: to, declared: make(map[string]bool)}
+	b.loops = append(b.loops, l)
+	defer func() {
+		b.loops = b.loops[:len(b.loops)-1]
+		delete(b.consts, s.Counter)
+	}()
+	for l.value = from; l.value < to; l.value++ {
+		l.names = make(map[string]string)
+		b.consts[s.Counter] = big.NewInt(int64(l.value))
+		if err = b.lowerStmts(s.Body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the signal of the name, which is its element of the current iteration if it is assigned in the body of a loop
+func (b *builder) resolve(name string) string {
+	for i := len(b.loops) - 1; i >= 0; i-- {
+		if s, ok := b.loops[i].names[name]; ok {
+			return s
+		}
+	}
+	return name
+}
+
+// declared returns if the name is already used by a signal, so it can not be assigned
+func (b *builder) declared(name string) bool {
+	if _, ok := b.consts[name]; ok || b.resolve(name) != name {
+		return true
+	}
+	if _, ok := b.arrays[name]; ok {
+		// the arrays of the loops are assigned by each iteration
+		for _, l := range b.loops {
+			if l.declared[name] {
+				return false
+			}
+		}
+		return true
+	}
+	return b.assigned[name]
+}
+
+// assignTarget returns the signal assigned by an assignment to name, which inside the loops is its element of the iterations, declaring the arrays on the first one
+func (b *builder) assignTarget(pos Pos, name string) (string, error) {
+	if _, ok := b.consts[name]; ok {
+		return "", b.errorf(pos, "can not assign the loop counter "+name)
+	}
+	s := name
+	for _, l := range b.loops {
+		if _, ok := b.arrays[s]; !ok {
+			if err := b.declareArray(pos, s, l.end); err != nil {
+				return "", err
+			}
+			l.declared[s] = true
+		} else if !l.declared[s] {
+			return "", b.errorf(pos, "signal "+name+" already assigned")
+		}
+		s = elementName(s, l.value)
+	}
+	return s, nil
+}
+
+// bind makes name to refer to the signal s in the current iteration of the loop
+func (b *builder) bind(name, s string) {
+	if len(b.loops) > 0 {
+		b.loops[len(b.loops)-1].names[name] = s
+	}
+}
@@ -0,0 +1,402 @@
+package circuitcompiler
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// lowerer holds the state shared by the lowering of the main function and of the inlined calls
+type lowerer struct {
+	funcs      map[string]*FuncDecl
+	callsCount int
+	compBits   int      // number of bits of the compared values
+	stack      []string // functions being inlined, to detect the recursion
+}
+
+// builder emits the Constraints and the Signals of a function body
+type builder struct {
+	l           *lowerer
+	fn          *FuncDecl
+	signals     []string
+	constraints []Constraint
+	assigned    map[string]bool
+	temps       map[string]int      // number of intermediate signals of each assigned signal
+	arrays      map[string]int      // length of the arrays of signals
+	loops       []*loop             // loops being unrolled
+	consts      map[string]*big.Int // counters of the loops being unrolled
+	ret         string              // returned value, once the return statement is lowered
+	hasRet      bool
+}
+
+func (b *builder) errorf(pos Pos, msg string) error {
+	return &Error{Pos: pos, Path: b.fn.Path, Msg: msg}
+}
+
+// lowerCircuit lowers the main function into the Circuit, inlining the calls to the other functions
+func lowerCircuit(funcs map[string]*FuncDecl, compBits int) (*Circuit, error) {
+	main, ok := funcs["main"]
+	if !ok {
+		return nil, &Error{Pos: Pos{Line: 1, Col: 1}, Msg: "no 'main' func declared"}
+	}
+	l := &lowerer{funcs: funcs, compBits: compBits, stack: []string{"main"}}
+	b := &builder{l: l, fn: main, signals: []string{"one"}, assigned: make(map[string]bool)}
+
+	circ := &Circuit{}
+	// one constraint for each input, first the public ones
+	for _, public := range []bool{true, false} {
+		for _, param := range main.Params {
+			if param.Public != public {
+				continue
+			}
+			if b.declared(param.Name) {
+				return nil, b.errorf(param.Pos, "input "+param.Name+" already declared")
+			}
+			// the arrays are a signal for each element
+			inputs := []string{param.Name}
+			if param.Len > 0 {
+				inputs = b.declareParamArray(param)
+			}
+			for _, input := range inputs {
+				b.assigned[input] = true
+				b.signals = append(b.signals, input)
+				b.constraints = append(b.constraints, Constraint{Op: "in", Out: input})
+				if public {
+					circ.PublicInputs = append(circ.PublicInputs, input)
+					circ.NPublic++
+				} else {
+					circ.PrivateInputs = append(circ.PrivateInputs, input)
+				}
+			}
+		}
+	}
+	if err := b.lowerBody(); err != nil {
+		return nil, err
+	}
+	if b.hasRet {
+		return nil, b.errorf(main.Body[len(main.Body)-1].Position(), "main can not return a value")
+	}
+
+	circ.Signals = b.signals
+	circ.Constraints = b.constraints
+	circ.NVars = len(circ.Signals)
+	circ.NSignals = len(circ.Signals)
+	return circ, nil
+}
+
+func (b *builder) lowerBody() error {
+	return b.lowerStmts(b.fn.Body)
+}
+
+func (b *builder) lowerStmts(stmts []Stmt) error {
+	for _, stmt := range stmts {
+		var err error
+		switch s := stmt.(type) {
+		case *AssignStmt:
+			var out string
+			if out, err = b.assignTarget(s.Pos, s.Out); err == nil && out != s.Out {
+				err = b.lowerAssign(&AssignStmt{Pos: s.Pos, Out: out, X: s.X})
+				b.bind(s.Out, out)
+			} else if err == nil {
+				err = b.lowerAssign(s)
+			}
+		case *ForStmt:
+			err = b.lowerFor(s)
+		case *CallStmt:
+			err = b.lowerCallStmt(s.Call)
+		case *IfStmt:
+			_, err = b.lowerIf(s, nil)
+		case *ReturnStmt:
+			var isArray bool
+			if b.ret, isArray, err = b.array(s.X); err == nil && !isArray {
+				var v value
+				if v, err = b.lowerExpr(s.X, "return"); err == nil {
+					b.ret = b.signalOf(v, "return")
+				}
+			}
+			b.hasRet = err == nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) addSignal(s string) {
+	if isVal, _ := isValue(s); isVal {
+		return
+	}
+	b.signals = addToArrayIfNotExist(b.signals, s)
+}
+
+func (b *builder) assign(pos Pos, out string) error {
+	if b.assigned[out] {
+		return b.errorf(pos, "signal "+out+" already assigned")
+	}
+	b.assigned[out] = true
+	return nil
+}
+
+// operand returns the name of the signal or the constant value of an atom expression
+func (b *builder) operand(x Expr) (string, bool) {
+	switch x := x.(type) {
+	case *Ident:
+		if k, ok := b.consts[x.Name]; ok {
+			return k.String(), true
+		}
+		s := b.resolve(x.Name)
+		return s, b.assigned[s]
+	case *Number:
+		return x.Value.String(), true
+	}
+	return "", false
+}
+
+func (b *builder) lowerAssign(s *AssignStmt) error {
+	if call, ok := s.X.(*CallExpr); ok && call.Func == "num2bits" {
+		return b.lowerNum2Bits(call, s)
+	}
+	if call, ok := s.X.(*CallExpr); ok {
+		isArray, err := b.lowerCall(call, s.Out)
+		if err != nil || isArray {
+			return err
+		}
+		return b.assign(s.Pos, s.Out)
+	}
+	if x, ok := s.X.(*BinaryExpr); ok && (x.Op == PLUS || x.Op == MINUS || x.Op == MULTIPLY || x.Op == DIVIDE) {
+		// the statements `out = v1 op v2` keep their single operation constraint
+		v1, ok1 := b.operand(x.X)
+		v2, ok2 := b.operand(x.Y)
+		if ok1 && ok2 {
+			if err := b.assign(s.Pos, s.Out); err != nil {
+				return err
+			}
+			b.emit(x.Op.String(), v1, v2, s.Out)
+			return nil
+		}
+	}
+	start := len(b.constraints)
+	v, err := b.lowerExpr(s.X, s.Out)
+	if err != nil {
+		return err
+	}
+	if err = b.assign(s.Pos, s.Out); err != nil {
+		return err
+	}
+	if t, ok := v.signal(); ok && strings.HasPrefix(t, s.Out+".") {
+		// the value is an intermediate signal of the statement, as the result of a division, which is renamed to out
+		b.renameSignal(t, s.Out, start)
+		return nil
+	}
+	b.emitValue(s.Out, v)
+	return nil
+}
+
+// renameSignal renames the signal from to the signal to, in the constraints from the start one
+func (b *builder) renameSignal(from, to string, start int) {
+	rename := func(s string) string {
+		if s == from {
+			return to
+		}
+		return s
+	}
+	renameLinearCombination := func(lc LinearCombination) {
+		for i := range lc {
+			lc[i].Signal = rename(lc[i].Signal)
+		}
+	}
+	for i := start; i < len(b.constraints); i++ {
+		c := &b.constraints[i]
+		c.Out, c.V1, c.V2 = rename(c.Out), rename(c.V1), rename(c.V2)
+		renameLinearCombination(c.A)
+		renameLinearCombination(c.B)
+		renameLinearCombination(c.C)
+		c.Literal = constraintLiteral(*c)
+	}
+	for i, s := range b.signals {
+		b.signals[i] = rename(s)
+	}
+}
+
+// emit adds the constraint out = v1 op v2 and its signals
+func (b *builder) emit(op, v1, v2, out string) {
+	b.constraints = append(b.constraints, Constraint{
+		Op:      op,
+		V1:      v1,
+		V2:      v2,
+		Out:     out,
+		Literal: out + "=" + v1 + op + v2,
+	})
+	b.addSignal(v1)
+	b.addSignal(v2)
+	b.addSignal(out)
+}
+
+// lowerCallStmt lowers the built-ins without assignment, equals(a, b) and assert_bool(b)
+func (b *builder) lowerCallStmt(call *CallExpr) error {
+	if call.Func == "assert_bool" {
+		return b.lowerAssertBool(call)
+	}
+	if call.Func != "equals" {
+		return b.errorf(call.Pos, "the result of "+call.Func+" must be assigned")
+	}
+	if len(call.Args) != 2 {
+		return b.errorf(call.Pos, "equals expects 2 arguments")
+	}
+	var vs [2]value
+	for i, arg := range call.Args {
+		v, err := b.lowerExpr(arg, "equals")
+		if err != nil {
+			return err
+		}
+		vs[i] = v
+	}
+	_, isVal1 := vs[0].constant()
+	_, isVal2 := vs[1].constant()
+	if isVal1 && isVal2 {
+		return b.errorf(call.Pos, "equals between two constants")
+	}
+	// a - b = 0, which checks the inputs instead of assigning them
+	b.assert(addLinearCombinations(b.linear(vs[0], "equals"), b.linear(vs[1], "equals"), big.NewInt(int64(-1))), constantTerm(big.NewInt(int64(1))), nil)
+	return nil
+}
+
+// lowerCall inlines the called function, giving unique names to its signals with the calls count as suffix, and mapping its inputs to the arguments and its returned signal to out. It returns if the returned signal is an array, which declares out as an array
+func (b *builder) lowerCall(call *CallExpr, out string) (bool, error) {
+	fn, ok := b.l.funcs[call.Func]
+	if !ok || call.Func == "main" {
+		return false, b.errorf(call.Pos, "using not declared function "+call.Func)
+	}
+	for _, name := range b.l.stack {
+		if name == call.Func {
+			return false, b.errorf(call.Pos, "recursive call to "+call.Func)
+		}
+	}
+	if len(call.Args) != len(fn.Params) {
+		return false, b.errorf(call.Pos, call.Func+" expects "+strconv.Itoa(len(fn.Params))+" arguments")
+	}
+	signalMap := make(map[string]string)
+	for i, arg := range call.Args {
+		if param := fn.Params[i]; param.Len > 0 {
+			// the elements of the array input are mapped to the elements of the array argument
+			s, err := b.arrayArg(arg, call.Func, param)
+			if err != nil {
+				return false, err
+			}
+			for j := 0; j < param.Len; j++ {
+				signalMap[elementName(param.Name, j)] = elementName(s, j)
+			}
+			continue
+		}
+		v, err := b.lowerExpr(arg, out)
+		if err != nil {
+			return false, err
+		}
+		signalMap[fn.Params[i].Name] = b.signalOf(v, out)
+	}
+
+	fb := &builder{l: b.l, fn: fn, assigned: make(map[string]bool)}
+	for _, param := range fn.Params {
+		if param.Len == 0 {
+			fb.assigned[param.Name] = true
+			continue
+		}
+		for _, element := range fb.declareParamArray(param) {
+			fb.assigned[element] = true
+		}
+	}
+	b.l.stack = append(b.l.stack, fn.Name)
+	err := fb.lowerBody()
+	b.l.stack = b.l.stack[:len(b.l.stack)-1]
+	if err != nil {
+		return false, err
+	}
+	if !fb.hasRet {
+		return false, b.errorf(call.Pos, "function "+fn.Name+" does not return a value")
+	}
+
+	// the signals of the call are prefixed with the function name and the calls count, f.N., which can not collide with the signals of the caller, as the identifiers have no dots
+	prefix := fn.Name + "." + strconv.Itoa(b.l.callsCount) + "."
+	b.l.callsCount++
+	rename := func(s string) string {
+		if v, ok := signalMap[s]; ok {
+			return v
+		}
+		if isVal, _ := isValue(s); isVal || s == "one" || s == "" {
+			return s
+		}
+		if s == fb.ret {
+			return out
+		}
+		if i := strings.Index(s, "["); i >= 0 && s[:i] == fb.ret && !strings.Contains(s, ".") {
+			// element of the returned array
+			return out + s[i:]
+		}
+		return prefix + s
+	}
+	renameLinearCombination := func(lc LinearCombination) LinearCombination {
+		var r LinearCombination
+		for _, t := range lc {
+			s := rename(t.Signal)
+			if isVal, v := isValue(s); isVal {
+				// input mapped to a constant
+				r = append(r, Term{Coeff: new(big.Int).Mul(t.Coeff, v), Signal: "one"})
+				continue
+			}
+			r = append(r, Term{Coeff: t.Coeff, Signal: s})
+		}
+		return r
+	}
+	for _, c := range fb.constraints {
+		nc := Constraint{
+			Op:  c.Op,
+			V1:  rename(c.V1),
+			V2:  rename(c.V2),
+			Out: rename(c.Out),
+			A:   renameLinearCombination(c.A),
+			B:   renameLinearCombination(c.B),
+			C:   renameLinearCombination(c.C),
+		}
+		nc.Literal = constraintLiteral(nc)
+		b.constraints = append(b.constraints, nc)
+	}
+	for _, s := range fb.signals {
+		b.addSignal(rename(s))
+	}
+	if n, ok := fb.arrays[fb.ret]; ok {
+		_, isInput := signalMap[elementName(fb.ret, 0)]
+		return true, b.returnArray(call.Pos, fb, n, isInput, rename, out)
+	}
+	if _, ok := signalMap[fb.ret]; ok || !fb.assigned[fb.ret] {
+		// the function returns an input or a constant
+		b.emit("*", rename(fb.ret), "1", out)
+	}
+	return false, nil
+}
+
+// returnArray declares out as the array of n signals returned by the inlined function of the builder fb, which can be an array of arrays, or an input array if isInput
+func (b *builder) returnArray(pos Pos, fb *builder, n int, isInput bool, rename func(string) string, out string) error {
+	if err := b.declareArray(pos, out, n); err != nil {
+		return err
+	}
+	if isInput {
+		// the elements of the input are copied
+		for i := 0; i < n; i++ {
+			b.emit("*", rename(elementName(fb.ret, i)), "1", elementName(out, i))
+			b.assigned[elementName(out, i)] = true
+		}
+		return nil
+	}
+	for name, n := range fb.arrays {
+		if strings.HasPrefix(name, fb.ret+"[") {
+			b.arrays[rename(name)] = n
+		}
+	}
+	for s := range fb.assigned {
+		if strings.HasPrefix(s, fb.ret+"[") && !strings.Contains(s, ".") {
+			b.assigned[rename(s)] = true
+		}
+	}
+	return nil
+}
@@ -3,352 +3,541 @@ package circuitcompiler
 import (
 	"bufio"
 	"errors"
-	"fmt"
 	"io"
+	"math/big"
 	"os"
-	"regexp"
 	"strconv"
-	"strings"
 )
 
-// Parser data structure holds the Scanner and the Parsing functions
-type Parser struct {
-	s   *Scanner
-	buf struct {
-		tok Token  // last read token
-		lit string // last read literal
-		n   int    // buffer size (max=1)
+// Error is an error in the circuit code, with its position
+type Error struct {
+	Pos  Pos
+	Path string // file of the error, empty for the main code
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.Path != "" {
+		return e.Path + ":" + e.Pos.String() + ": " + e.Msg
 	}
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// DefaultComparisonBits is the default number of bits of the values compared with < <= > >=
+const DefaultComparisonBits = 252
+
+// Parser is the recursive descent parser of the circuit language, it holds the Scanner and the current token
+type Parser struct {
+	// ComparisonBits is the number of bits of the values compared with < <= > >=, which must fit in it. DefaultComparisonBits is used if it is 0
+	ComparisonBits int
+
+	s    *Scanner
+	path string // path of the parsed file, used in the errors
+
+	pos Pos   // position of the current token
+	tok Token // current token
+	lit string
 }
 
 // NewParser creates a new parser from a io.Reader
 func NewParser(r io.Reader) *Parser {
-	return &Parser{s: NewScanner(r)}
+	p := &Parser{s: NewScanner(r)}
+	p.next()
+	return p
 }
 
-func (p *Parser) scan() (tok Token, lit string) {
-	// if there is a token in the buffer return it
-	if p.buf.n != 0 {
-		p.buf.n = 0
-		return p.buf.tok, p.buf.lit
+func (p *Parser) next() {
+	p.pos, p.tok, p.lit = p.s.Scan()
+}
+
+func (p *Parser) errorf(pos Pos, msg string) error {
+	return &Error{Pos: pos, Path: p.path, Msg: msg}
+}
+
+// unexpected returns the error of an unexpected current token
+func (p *Parser) unexpected(expected string) error {
+	found := p.tok.String()
+	if p.tok == IDENT || p.tok == CONST || p.tok == ILLEGAL {
+		found += " " + p.lit
 	}
-	tok, lit = p.s.scan()
+	return p.errorf(p.pos, "expected "+expected+", found "+found)
+}
 
-	p.buf.tok, p.buf.lit = tok, lit
+// expect checks that the current token is tok and moves to the next one, returning the literal
+func (p *Parser) expect(tok Token) (string, error) {
+	if p.tok != tok {
+		return "", p.unexpected(tok.String())
+	}
+	lit := p.lit
+	p.next()
+	return lit, nil
+}
+
+// expectEndOfLine checks that the statement ends with a newline or with the end of the file
+func (p *Parser) expectEndOfLine() error {
+	if p.tok == EOF {
+		return nil
+	}
+	_, err := p.expect(NEWLINE)
+	return err
+}
 
-	return
+// endOfStmt checks the end of the line of the statement, except for the for statement, which ends at the next line that is not indented more than it
+func (p *Parser) endOfStmt(stmt Stmt) error {
+	if _, ok := stmt.(*ForStmt); ok {
+		return nil
+	}
+	return p.expectEndOfLine()
 }
 
-func (p *Parser) unscan() {
-	p.buf.n = 1
+// ParseFile parses the circuit code into its AST, without loading the imported files
+func (p *Parser) ParseFile() (*File, error) {
+	file := &File{}
+	if p.tok == NEWLINE {
+		p.next()
+	}
+	for p.tok != EOF {
+		switch p.tok {
+		case IMPORT:
+			imp, err := p.parseImport()
+			if err != nil {
+				return nil, err
+			}
+			file.Imports = append(file.Imports, imp)
+		case FUNC:
+			fn, err := p.parseFunc()
+			if err != nil {
+				return nil, err
+			}
+			file.Funcs = append(file.Funcs, fn)
+		default:
+			return nil, p.unexpected("func or import")
+		}
+	}
+	return file, nil
 }
 
-func (p *Parser) scanIgnoreWhitespace() (tok Token, lit string) {
-	tok, lit = p.scan()
-	if tok == WS {
-		tok, lit = p.scan()
+// parseImport parses `import "path"`
+func (p *Parser) parseImport() (*ImportDecl, error) {
+	imp := &ImportDecl{Pos: p.pos}
+	p.next()
+	path, err := p.expect(STRING)
+	if err != nil {
+		return nil, err
 	}
-	return
+	imp.Path = path
+	return imp, p.expectEndOfLine()
 }
 
-// parseLine parses the current line
-func (p *Parser) parseLine() (*Constraint, error) {
-	/*
-		in this version,
-		line will be for example s3 = s1 * s4
-		this is:
-		val eq val op val
-	*/
-	c := &Constraint{}
-	tok, lit := p.scanIgnoreWhitespace()
-	c.Out = lit
-	c.Literal += lit
-
-	if c.Literal == "func" {
-		// format: `func name(in):`
-		line, err := p.s.r.ReadString(':')
-		if err != nil {
-			return c, err
-		}
-		// get func name
-		fName := strings.Split(line, "(")[0]
-		fName = strings.Replace(fName, " ", "", -1)
-		fName = strings.Replace(fName, "	", "", -1)
-		c.V1 = fName // so, the name of the func will be in c.V1
-
-		// read string inside ( )
-		rgx := regexp.MustCompile(`\((.*?)\)`)
-		insideParenthesis := rgx.FindStringSubmatch(line)
-		varsString := strings.Replace(insideParenthesis[1], " ", "", -1)
-		allInputs := strings.Split(varsString, ",")
-
-		// from allInputs, get the private and the public separated
-		for _, in := range allInputs {
-			if strings.Contains(in, "private") {
-				input := strings.Replace(in, "private", "", -1)
-				c.PrivateInputs = append(c.PrivateInputs, input)
-			} else if strings.Contains(in, "public") {
-				input := strings.Replace(in, "public", "", -1)
-				c.PublicInputs = append(c.PublicInputs, input)
-			} else {
-				// TODO give more info about the circuit code error
-				fmt.Println("error on declaration of public and private inputs")
-				os.Exit(0)
+// parseFunc parses `func name(private a, public b):` and the statements of its body, which ends after the return statement, or at the next declaration
+func (p *Parser) parseFunc() (*FuncDecl, error) {
+	fn := &FuncDecl{Pos: p.pos, Path: p.path}
+	p.next()
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	fn.Name = name
+	if _, err = p.expect(LPAREN); err != nil {
+		return nil, err
+	}
+	for p.tok != RPAREN {
+		if len(fn.Params) > 0 {
+			if _, err = p.expect(COMMA); err != nil {
+				return nil, err
+			}
+		}
+		param := Param{Pos: p.pos}
+		switch p.tok {
+		case PUBLIC:
+			param.Public = true
+		case PRIVATE:
+		default:
+			return nil, p.errorf(p.pos, "inputs must be declared as private or public")
+		}
+		p.next()
+		if param.Name, err = p.expect(IDENT); err != nil {
+			return nil, err
+		}
+		if p.tok == LBRACK {
+			// array input, name[len]
+			p.next()
+			pos, lit := p.pos, p.lit
+			if _, err = p.expect(CONST); err != nil {
+				return nil, err
+			}
+			if param.Len, err = strconv.Atoi(lit); err != nil || param.Len < 1 {
+				return nil, p.errorf(pos, "the length of an array must be a positive constant")
+			}
+			if _, err = p.expect(RBRACK); err != nil {
+				return nil, err
 			}
 		}
-		return c, nil
+		fn.Params = append(fn.Params, param)
 	}
-	if c.Literal == "equals" {
-		// format: `equals(a, b)`
-		line, err := p.s.r.ReadString(')')
+	p.next()
+	if _, err = p.expect(COLON); err != nil {
+		return nil, err
+	}
+	if err = p.expectEndOfLine(); err != nil {
+		return nil, err
+	}
+
+	for p.tok != EOF && p.tok != FUNC && p.tok != IMPORT {
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		fn.Body = append(fn.Body, stmt)
+		if err = p.endOfStmt(stmt); err != nil {
+			return nil, err
+		}
+		if _, ok := stmt.(*ReturnStmt); ok {
+			break
+		}
+	}
+	return fn, nil
+}
+
+// parseStmt parses an assignment `out = expr`, a call `f(args)`, a `return expr`, an `if cond { ... } else { ... }` or a `for i in from..to:`
+func (p *Parser) parseStmt() (Stmt, error) {
+	pos := p.pos
+	switch p.tok {
+	case IF:
+		return p.parseIf()
+	case FOR:
+		return p.parseFor()
+	case RETURN:
+		p.next()
+		x, err := p.parseExpr()
 		if err != nil {
-			return c, err
-		}
-		// read string inside ( )
-		rgx := regexp.MustCompile(`\((.*?)\)`)
-		insideParenthesis := rgx.FindStringSubmatch(line)
-		varsString := strings.Replace(insideParenthesis[1], " ", "", -1)
-		params := strings.Split(varsString, ",")
-		c.V1 = params[0]
-		c.V2 = params[1]
-		return c, nil
-	}
-	if c.Literal == "return" {
-		_, varToReturn := p.scanIgnoreWhitespace()
-		c.Out = varToReturn
-		return c, nil
-	}
-	if c.Literal == "import" {
-		line, err := p.s.r.ReadString('\n')
+			return nil, err
+		}
+		return &ReturnStmt{Pos: pos, X: x}, nil
+	case IDENT:
+		name := p.lit
+		p.next()
+		if p.tok == LPAREN {
+			call, err := p.parseCall(pos, name)
+			if err != nil {
+				return nil, err
+			}
+			return &CallStmt{Call: call}, nil
+		}
+		if _, err := p.expect(EQ); err != nil {
+			return nil, err
+		}
+		x, err := p.parseExpr()
 		if err != nil {
-			return c, err
-		}
-		// read string inside " "
-		path := strings.TrimLeft(strings.TrimRight(line, `"`), `"`)
-		path = strings.Replace(path, `"`, "", -1)
-		path = strings.Replace(path, " ", "", -1)
-		path = strings.Replace(path, "\n", "", -1)
-		c.Out = path
-		return c, nil
-	}
-
-	_, lit = p.scanIgnoreWhitespace() // skip =
-	c.Literal += lit
-
-	// v1
-	_, lit = p.scanIgnoreWhitespace()
-
-	// check if lit is a name of a func that we have declared
-	if _, ok := circuits[lit]; ok {
-		// if inside, is calling a declared function
-		c.Literal = "call"
-		c.Op = lit // c.Op handles the name of the function called
-		// put the inputs of the call into the c.PrivateInputs
-		// format: `funcname(a, b)`
-		line, err := p.s.r.ReadString(')')
+			return nil, err
+		}
+		return &AssignStmt{Pos: pos, Out: name, X: x}, nil
+	}
+	return nil, p.unexpected("statement")
+}
+
+// parseIf parses `if cond {`, the statements of the block until `}`, and the else block, which can be another if statement
+func (p *Parser) parseIf() (*IfStmt, error) {
+	s := &IfStmt{Pos: p.pos}
+	p.next()
+	var err error
+	if s.Cond, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if s.Then, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(ELSE); err != nil {
+		return nil, err
+	}
+	if p.tok == IF {
+		elseIf, err := p.parseIf()
 		if err != nil {
-			fmt.Println("ERR", err)
-			return c, err
-		}
-		// read string inside ( )
-		rgx := regexp.MustCompile(`\((.*?)\)`)
-		insideParenthesis := rgx.FindStringSubmatch(line)
-		varsString := strings.Replace(insideParenthesis[1], " ", "", -1)
-		params := strings.Split(varsString, ",")
-		c.PrivateInputs = params
-		return c, nil
-
-	}
-
-	c.V1 = lit
-	c.Literal += lit
-	// operator
-	_, lit = p.scanIgnoreWhitespace()
-	if lit == "(" {
-		panic(errors.New("using not declared function"))
-	}
-	c.Op = lit
-	c.Literal += lit
-	// v2
-	_, lit = p.scanIgnoreWhitespace()
-	c.V2 = lit
-	c.Literal += lit
-	if tok == EOF {
-		return nil, errors.New("eof in parseline")
-	}
-	return c, nil
+			return nil, err
+		}
+		s.Else = []Stmt{elseIf}
+		return s, nil
+	}
+	if s.Else, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
-func existInArray(arr []string, elem string) bool {
-	for _, v := range arr {
-		if v == elem {
-			return true
+// parseFor parses `for i in from..to:` and the statements of its body, which are the following lines indented more than the for
+func (p *Parser) parseFor() (*ForStmt, error) {
+	s := &ForStmt{Pos: p.pos}
+	p.next()
+	var err error
+	if s.Counter, err = p.expect(IDENT); err != nil {
+		return nil, err
+	}
+	if p.tok != IDENT || p.lit != "in" {
+		return nil, p.unexpected("in")
+	}
+	p.next()
+	if s.From, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(DOTDOT); err != nil {
+		return nil, err
+	}
+	if s.To, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(COLON); err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(NEWLINE); err != nil {
+		return nil, err
+	}
+	for p.tok != EOF && p.pos.Col > s.Pos.Col {
+		if p.tok == RETURN {
+			return nil, p.errorf(p.pos, "return inside a for block")
+		}
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		s.Body = append(s.Body, stmt)
+		if err = p.endOfStmt(stmt); err != nil {
+			return nil, err
 		}
 	}
-	return false
+	if len(s.Body) == 0 {
+		return nil, p.unexpected("indented statement")
+	}
+	return s, nil
 }
 
-func addToArrayIfNotExist(arr []string, elem string) []string {
-	for _, v := range arr {
-		if v == elem {
-			return arr
+// parseBlock parses the statements between { and }, each one in its own line
+func (p *Parser) parseBlock() ([]Stmt, error) {
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(NEWLINE); err != nil {
+		return nil, err
+	}
+	var stmts []Stmt
+	for p.tok != RBRACE {
+		if p.tok == RETURN {
+			return nil, p.errorf(p.pos, "return inside an if block")
+		}
+		stmt, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+		if err = p.endOfStmt(stmt); err != nil {
+			return nil, err
 		}
 	}
-	arr = append(arr, elem)
-	return arr
+	p.next()
+	return stmts, nil
 }
 
-func subsIfInMap(original string, m map[string]string) string {
-	if v, ok := m[original]; ok {
-		return v
+// parseCall parses the arguments of a call, being the current token the left parenthesis
+func (p *Parser) parseCall(pos Pos, name string) (*CallExpr, error) {
+	call := &CallExpr{Pos: pos, Func: name}
+	p.next()
+	for p.tok != RPAREN {
+		if len(call.Args) > 0 {
+			if _, err := p.expect(COMMA); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
 	}
-	return original
+	p.next()
+	return call, nil
 }
 
-var circuits map[string]*Circuit
+// parseExpr parses an expression with the precedence, from lower to higher: c ? x : y (right associative), a comparison (< <= > >= !=), + -, * /, unary -, ^ (right associative)
+func (p *Parser) parseExpr() (Expr, error) {
+	c, err := p.parseComparison()
+	if err != nil || p.tok != QUESTION {
+		return c, err
+	}
+	x := &CondExpr{Pos: p.pos, Cond: c}
+	p.next()
+	if x.X, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(COLON); err != nil {
+		return nil, err
+	}
+	if x.Y, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
 
-// Parse parses the lines and returns the compiled Circuit
-func (p *Parser) Parse() (*Circuit, error) {
-	// funcsMap is a map holding the functions names and it's content as Circuit
-	circuits = make(map[string]*Circuit)
-	mainExist := false
-	circuits["main"] = &Circuit{}
-	callsCount := 0
-
-	circuits["main"].Signals = append(circuits["main"].Signals, "one")
-	nInputs := 0
-	currCircuit := ""
-	for {
-		constraint, err := p.parseLine()
-		if err != nil {
-			break
+func (p *Parser) parseComparison() (Expr, error) {
+	x, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	switch p.tok {
+	case LT, LE, GT, GE, NEQ:
+		b := &BinaryExpr{Pos: p.pos, Op: p.tok, X: x}
+		p.next()
+		if b.Y, err = p.parseSum(); err != nil {
+			return nil, err
 		}
-		if constraint.Literal == "func" {
-			// the name of the func is in constraint.V1
-			// check if the name of func is main
-			if constraint.V1 != "main" {
-				currCircuit = constraint.V1
-				circuits[currCircuit] = &Circuit{}
-				circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *constraint)
-				continue
-			}
-			currCircuit = "main"
-			mainExist = true
-			// l, _ := json.Marshal(constraint)
-			// fmt.Println(string(l))
-
-			// one constraint for each input
-			for _, in := range constraint.PublicInputs {
-				newConstr := &Constraint{
-					Op:  "in",
-					Out: in,
-				}
-				circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *newConstr)
-				nInputs++
-				circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, in)
-				circuits[currCircuit].NPublic++
-			}
-			for _, in := range constraint.PrivateInputs {
-				newConstr := &Constraint{
-					Op:  "in",
-					Out: in,
-				}
-				circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *newConstr)
-				nInputs++
-				circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, in)
-			}
-			circuits[currCircuit].PublicInputs = constraint.PublicInputs
-			circuits[currCircuit].PrivateInputs = constraint.PrivateInputs
-			continue
+		return b, nil
+	}
+	return x, nil
+}
+
+func (p *Parser) parseSum() (Expr, error) {
+	return p.parseBinary(PLUS, MINUS, p.parseTerm)
+}
+
+func (p *Parser) parseTerm() (Expr, error) {
+	return p.parseBinary(MULTIPLY, DIVIDE, p.parseUnary)
+}
+
+// parseBinary parses the left associative operations op1 and op2 between the operands given by parseOperand
+func (p *Parser) parseBinary(op1, op2 Token, parseOperand func() (Expr, error)) (Expr, error) {
+	x, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == op1 || p.tok == op2 {
+		b := &BinaryExpr{Pos: p.pos, Op: p.tok, X: x}
+		p.next()
+		if b.Y, err = parseOperand(); err != nil {
+			return nil, err
 		}
-		if constraint.Literal == "equals" {
-			constr1 := &Constraint{
-				Op:      "*",
-				V1:      constraint.V2,
-				V2:      "1",
-				Out:     constraint.V1,
-				Literal: "equals(" + constraint.V1 + ", " + constraint.V2 + "): " + constraint.V1 + "==" + constraint.V2 + " * 1",
-			}
-			circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *constr1)
-			constr2 := &Constraint{
-				Op:      "*",
-				V1:      constraint.V1,
-				V2:      "1",
-				Out:     constraint.V2,
-				Literal: "equals(" + constraint.V1 + ", " + constraint.V2 + "): " + constraint.V2 + "==" + constraint.V1 + " * 1",
-			}
-			circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *constr2)
-			continue
+		x = b
+	}
+	return x, nil
+}
+
+func (p *Parser) parseUnary() (Expr, error) {
+	if p.tok == MINUS {
+		u := &UnaryExpr{Pos: p.pos, Op: MINUS}
+		p.next()
+		var err error
+		if u.X, err = p.parseUnary(); err != nil {
+			return nil, err
 		}
-		if constraint.Literal == "return" {
-			currCircuit = ""
-			continue
+		return u, nil
+	}
+	return p.parsePower()
+}
+
+func (p *Parser) parsePower() (Expr, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok == EXP {
+		b := &BinaryExpr{Pos: p.pos, Op: EXP, X: x}
+		p.next()
+		if b.Y, err = p.parseUnary(); err != nil {
+			return nil, err
 		}
-		if constraint.Literal == "call" {
-			callsCountStr := strconv.Itoa(callsCount)
-			// for each of the constraints of the called circuit
-			// add it into the current circuit
-			signalMap := make(map[string]string)
-			for i, s := range constraint.PrivateInputs {
-				// signalMap[s] = circuits[constraint.Op].Constraints[0].PrivateInputs[i]
-				signalMap[circuits[constraint.Op].Constraints[0].PrivateInputs[i]+callsCountStr] = s
-			}
-			// add out to map
-			signalMap[circuits[constraint.Op].Constraints[len(circuits[constraint.Op].Constraints)-1].Out+callsCountStr] = constraint.Out
-
-			for i := 1; i < len(circuits[constraint.Op].Constraints); i++ {
-				c := circuits[constraint.Op].Constraints[i]
-				// add constraint, puting unique names to vars
-				nc := &Constraint{
-					Op:      c.Op,
-					V1:      subsIfInMap(c.V1+callsCountStr, signalMap),
-					V2:      subsIfInMap(c.V2+callsCountStr, signalMap),
-					Out:     subsIfInMap(c.Out+callsCountStr, signalMap),
-					Literal: "",
-				}
-				nc.Literal = nc.Out + "=" + nc.V1 + nc.Op + nc.V2
-				circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *nc)
-			}
-			for _, s := range circuits[constraint.Op].Signals {
-				circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, subsIfInMap(s+callsCountStr, signalMap))
-			}
-			callsCount++
-			continue
+		return b, nil
+	}
+	return x, nil
+}
 
+// parsePrimary parses a constant, a signal, an element of an array, a call or an expression between parentheses
+func (p *Parser) parsePrimary() (Expr, error) {
+	pos := p.pos
+	switch p.tok {
+	case CONST:
+		v, ok := new(big.Int).SetString(p.lit, 10)
+		if !ok {
+			return nil, p.errorf(pos, "invalid constant "+p.lit)
 		}
-		if constraint.Literal == "import" {
-			circuitFile, err := os.Open(constraint.Out)
-			if err != nil {
-				panic(errors.New("imported path error: " + constraint.Out))
+		p.next()
+		return &Number{Pos: pos, Value: v}, nil
+	case IDENT:
+		name := p.lit
+		p.next()
+		if p.tok == LPAREN {
+			return p.parseCall(pos, name)
+		}
+		if p.tok == LBRACK {
+			x := &IndexExpr{Pos: pos, Name: name}
+			for p.tok == LBRACK {
+				p.next()
+				index, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				if _, err = p.expect(RBRACK); err != nil {
+					return nil, err
+				}
+				x.Indexes = append(x.Indexes, index)
 			}
-			parser := NewParser(bufio.NewReader(circuitFile))
-			_, err = parser.Parse() // this will add the imported file funcs into the `circuits` map
-			continue
+			return x, nil
 		}
-
-		circuits[currCircuit].Constraints = append(circuits[currCircuit].Constraints, *constraint)
-		isVal, _ := isValue(constraint.V1)
-		if !isVal {
-			circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, constraint.V1)
+		return &Ident{Pos: pos, Name: name}, nil
+	case LPAREN:
+		p.next()
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
 		}
-		isVal, _ = isValue(constraint.V2)
-		if !isVal {
-			circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, constraint.V2)
+		if _, err = p.expect(RPAREN); err != nil {
+			return nil, err
 		}
+		return x, nil
+	}
+	return nil, p.unexpected("expression")
+}
 
-		circuits[currCircuit].Signals = addToArrayIfNotExist(circuits[currCircuit].Signals, constraint.Out)
+// Parse parses the circuit code and the imported files, and lowers the main function into the compiled Circuit
+func (p *Parser) Parse() (*Circuit, error) {
+	funcs := make(map[string]*FuncDecl)
+	if err := p.parseWithImports(funcs, map[string]bool{}); err != nil {
+		return nil, err
 	}
-	circuits["main"].NVars = len(circuits["main"].Signals)
-	circuits["main"].NSignals = len(circuits["main"].Signals)
-	if mainExist == false {
-		return circuits["main"], errors.New("No 'main' func declared")
+	bits := p.ComparisonBits
+	if bits == 0 {
+		bits = DefaultComparisonBits
 	}
-	return circuits["main"], nil
+	if bits < 1 || bits > DefaultComparisonBits {
+		return nil, errors.New("the comparison bits must be between 1 and " + strconv.Itoa(DefaultComparisonBits))
+	}
+	return lowerCircuit(funcs, bits)
 }
-func copyArray(in []string) []string { // tmp
-	var out []string
-	for _, e := range in {
-		out = append(out, e)
+
+// parseWithImports parses the file and the imported files, adding their functions to funcs. The paths of the imports are relative to the current directory
+func (p *Parser) parseWithImports(funcs map[string]*FuncDecl, imported map[string]bool) error {
+	file, err := p.ParseFile()
+	if err != nil {
+		return err
+	}
+	for _, fn := range file.Funcs {
+		if _, ok := funcs[fn.Name]; ok {
+			return p.errorf(fn.Pos, "function "+fn.Name+" already declared")
+		}
+		funcs[fn.Name] = fn
+	}
+	for _, imp := range file.Imports {
+		if imported[imp.Path] {
+			continue
+		}
+		imported[imp.Path] = true
+		f, err := os.Open(imp.Path)
+		if err != nil {
+			return p.errorf(imp.Pos, "imported path error: "+err.Error())
+		}
+		parser := NewParser(bufio.NewReader(f))
+		parser.path = imp.Path
+		err = parser.parseWithImports(funcs, imported)
+		f.Close()
+		if err != nil {
+			return err
+		}
 	}
-	return out
+	return nil
 }
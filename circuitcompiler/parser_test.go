@@ -0,0 +1,173 @@
+package circuitcompiler
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanner(t *testing.T) {
+	code := `func main(private a, public b1): // comment
+	/* multi
+	line */
+	c = a * 123
+	import "file.circuit"
+`
+	s := NewScanner(strings.NewReader(code))
+	type scanned struct {
+		pos Pos
+		tok Token
+		lit string
+	}
+	expected := []scanned{
+		{Pos{1, 1}, FUNC, "func"},
+		{Pos{1, 6}, IDENT, "main"},
+		{Pos{1, 10}, LPAREN, "("},
+		{Pos{1, 11}, PRIVATE, "private"},
+		{Pos{1, 19}, IDENT, "a"},
+		{Pos{1, 20}, COMMA, ","},
+		{Pos{1, 22}, PUBLIC, "public"},
+		{Pos{1, 29}, IDENT, "b1"},
+		{Pos{1, 31}, RPAREN, ")"},
+		{Pos{1, 32}, COLON, ":"},
+		{Pos{1, 44}, NEWLINE, "\n"},
+		{Pos{4, 2}, IDENT, "c"},
+		{Pos{4, 4}, EQ, "="},
+		{Pos{4, 6}, IDENT, "a"},
+		{Pos{4, 8}, MULTIPLY, "*"},
+		{Pos{4, 10}, CONST, "123"},
+		{Pos{4, 13}, NEWLINE, "\n"},
+		{Pos{5, 2}, IMPORT, "import"},
+		{Pos{5, 9}, STRING, "file.circuit"},
+		{Pos{5, 23}, NEWLINE, "\n"},
+		{Pos{6, 1}, EOF, ""},
+	}
+	for _, e := range expected {
+		pos, tok, lit := s.Scan()
+		assert.Equal(t, e, scanned{pos, tok, lit})
+	}
+
+	_, tok, lit := NewScanner(strings.NewReader("12a")).Scan()
+	assert.Equal(t, ILLEGAL, tok)
+	assert.Equal(t, "12a", lit)
+
+	// the unterminated comment is not skipped until the end of the file
+	s = NewScanner(strings.NewReader("a = 1\n  /* b = 2\n"))
+	for _, e := range []scanned{{Pos{1, 1}, IDENT, "a"}, {Pos{1, 3}, EQ, "="}, {Pos{1, 5}, CONST, "1"}, {Pos{1, 6}, NEWLINE, "\n"}, {Pos{2, 3}, ILLEGAL, "/*"}} {
+		pos, tok, lit := s.Scan()
+		assert.Equal(t, e, scanned{pos, tok, lit})
+	}
+
+	s = NewScanner(strings.NewReader("< <= > >= != ! { } ? if else for .."))
+	for _, e := range []Token{LT, LE, GT, GE, NEQ, ILLEGAL, LBRACE, RBRACE, QUESTION, IF, ELSE, FOR, DOTDOT} {
+		_, tok, _ := s.Scan()
+		assert.Equal(t, e, tok)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	code := `
+	func main(private a, public b):
+		c = (a + 2) * -b ^ 3
+		equals(b, c)
+	`
+	file, err := NewParser(strings.NewReader(code)).ParseFile()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(file.Funcs))
+	fn := file.Funcs[0]
+	assert.Equal(t, "main", fn.Name)
+	assert.Equal(t, []Param{{Pos{2, 12}, "a", false, 0}, {Pos{2, 23}, "b", true, 0}}, fn.Params)
+	assert.Equal(t, 2, len(fn.Body))
+
+	// c = (a + 2) * (-(b ^ 3))
+	assign := fn.Body[0].(*AssignStmt)
+	assert.Equal(t, "c", assign.Out)
+	mul := assign.X.(*BinaryExpr)
+	assert.Equal(t, MULTIPLY, mul.Op)
+	assert.Equal(t, Pos{3, 15}, mul.Pos)
+	add := mul.X.(*BinaryExpr)
+	assert.Equal(t, PLUS, add.Op)
+	assert.Equal(t, "a", add.X.(*Ident).Name)
+	assert.Equal(t, 0, add.Y.(*Number).Value.Cmp(big.NewInt(int64(2))))
+	neg := mul.Y.(*UnaryExpr)
+	exp := neg.X.(*BinaryExpr)
+	assert.Equal(t, EXP, exp.Op)
+	assert.Equal(t, "b", exp.X.(*Ident).Name)
+
+	call := fn.Body[1].(*CallStmt).Call
+	assert.Equal(t, "equals", call.Func)
+	assert.Equal(t, 2, len(call.Args))
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		code string
+		err  string
+	}{
+		{"func main(private a):\n\tb = a *\n", "2:9: expected expression, found newline"},
+		{"func main(a):\n", "1:11: inputs must be declared as private or public"},
+		{"func main(private a)\n\tb = a\n", "1:21: expected :, found newline"},
+		{"func main(private a):\n\tb = c * a\n", "2:6: using signal c before it's set"},
+		{"func main(private a):\n\tb = f(a)\n", "2:6: using not declared function f"},
+		{"func main(private a):\n\tb = a * a\n\tb = a + a\n", "3:2: signal b already assigned"},
+		{"func main(private a):\n\tb = (a + 1) / 0\n", "2:14: division by zero"},
+		{"func main(private a):\n\tb = 2 ^ a\n", "2:8: the exponent must be a non negative constant"},
+		{"func main(private a):\n\tb = num2bits(a, 2)\n\tc = b[2]\n", "3:8: index 2 out of range of b[2]"},
+		{"func main(private a):\n\tb = num2bits(a, 254)\n", "2:18: the number of bits must be between 1 and 253"},
+		{"func main(private a):\n\tb = num2bits(a, 2)\n\tc = b + 1\n", "3:6: b is an array, its elements are used as b[i]"},
+		{"func main(private a, private b):\n\tc = a < b < 2\n", "2:12: expected newline, found <"},
+		{"func main(private a):\n\tif a {\n\t\tb = a\n\t} else {\n\t\tc = a\n\t}\n", "2:2: signal b is not assigned in the else block"},
+		{"func main(private a):\n\tif a {\n\t\tb = a\n\t}\n", "4:3: expected else, found newline"},
+		{"func main(private a):\n\tif 2 {\n\t\tb = a\n\t} else {\n\t\tb = 1\n\t}\n", "2:5: the condition must be 0 or 1"},
+		{"func main(private a):\n\tb = a\n\tif a {\n\t\tb = a\n\t} else {\n\t\tb = 1\n\t}\n", "4:3: signal b already assigned"},
+		{"func main(private a):\n\tif a {\n\t\tequals(a, 1)\n\t} else {\n\t}\n", "3:3: only assignments are allowed inside an if block"},
+		{"func f(private a):\n\tif a {\n\t\treturn a\n\t} else {\n\t}\nfunc main(private a):\n\tb = f(a)\n", "3:3: return inside an if block"},
+		{"func main(private a):\n\tfor i in 0..2:\n\t\ti = a\n", "3:3: can not assign the loop counter i"},
+		{"func main(private a):\n\tfor i in 0..a:\n\t\tb = a\n", "2:14: the loop bound must be a constant"},
+		{"func main(private a):\n\tb = a\n\tfor i in 0..2:\n\t\tb = a * i\n", "4:3: signal b already assigned"},
+		{"func main(private a):\n\tfor i in 0..2:\n\t\tb = b[i-1] + a\n", "3:10: index -1 out of range of b[2]"},
+		{"func main(private a):\n\tfor i in 0..2:\n\tb = a\n", "3:2: expected indented statement, found identifier b"},
+		{"func main(private a):\n\tfor i in 0..2:\n\t\tfor i in 0..2:\n\t\t\tb = a\n", "3:3: i already declared"},
+		{"func main(private a[0]):\n\tb = a\n", "1:21: the length of an array must be a positive constant"},
+		{"func f(private v[2]):\n\treturn v\nfunc main(private a):\n\tb = f(a)\n", "4:8: the argument v of f must be an array of 2 signals"},
+		{"func f(private v[2]):\n\treturn v\nfunc main(private a[3]):\n\tb = f(a)\n", "4:8: the argument v of f must be an array of 2 signals"},
+		{"func f(private v[2]):\n\treturn v\nfunc main(private a[2]):\n\tb = f(a) + 1\n", "4:6: the result of f is an array, it must be assigned"},
+		{"func main(private a[2], public a):\n\tb = a[0]\n", "1:11: input a already declared"},
+		{"func f(private a):\n\tb = f(a)\n\treturn b\nfunc main(private a):\n\tb = f(a)\n", "2:6: recursive call to f"},
+		{"func f(private a):\n\tb = a * a\n", "1:1: no 'main' func declared"},
+		{"func main(private a):\n\tb = a * a\n\t/* c = b\n", "3:2: expected statement, found ILLEGAL /*"},
+		{"import \"not-found.circuit\"\nfunc main(private a):\n\tb = a * a\n", "1:1: imported path error: open not-found.circuit: no such file or directory"},
+	}
+	for _, tc := range testCases {
+		_, err := NewParser(strings.NewReader(tc.code)).Parse()
+		if assert.NotNil(t, err, tc.code) {
+			assert.Equal(t, tc.err, err.Error(), tc.code)
+		}
+	}
+}
+
+func TestCircuitWithComments(t *testing.T) {
+	code := `
+	// y = x^3 + x + 5
+	func main(private s0, public s1):
+		s2 = s0 * s0 // x^2
+		s3 = s2 * s0
+
+		/* the constant
+		is 5 */
+		s4 = s3 + s0
+		s5 = s4 + 5
+		equals(s1, s5)
+		out = 1 * 1
+	`
+	circuit, err := NewParser(strings.NewReader(code)).Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "s1", "s0", "s2", "s3", "s4", "s5", "out"}, circuit.Signals)
+	assert.Equal(t, "s5=s4+5", circuit.Constraints[5].Literal)
+
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(35))})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, w[6].Cmp(big.NewInt(int64(35))))
+}
@@ -0,0 +1,214 @@
+package circuitcompiler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/arnaucube/go-snark-study/r1csqap"
+)
+
+// sections of the iden3 .r1cs file
+const (
+	r1csSectionHeader      = 1
+	r1csSectionConstraints = 2
+	r1csSectionWire2Label  = 3
+	r1csVersion            = 1
+)
+
+var r1csMagic = []byte("r1cs")
+
+// r1csPrime is the order R of the scalar field of the BN128 curve, the only one supported for the .r1cs and .wtns files
+var r1csPrime = witnessField.Q
+
+// r1csReader reads the values of a .r1cs section, keeping the first error
+type r1csReader struct {
+	b   []byte
+	n8  int
+	err error
+}
+
+func (rr *r1csReader) read(n int) []byte {
+	if rr.err != nil {
+		return nil
+	}
+	if n < 0 || len(rr.b) < n {
+		rr.err = errors.New("r1cs section too short")
+		return nil
+	}
+	b := rr.b[:n]
+	rr.b = rr.b[n:]
+	return b
+}
+
+func (rr *r1csReader) uint32() uint32 {
+	b := rr.read(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (rr *r1csReader) uint64() uint64 {
+	b := rr.read(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// le reads a little-endian value of n8 bytes
+func (rr *r1csReader) le() *big.Int {
+	b := rr.read(rr.n8)
+	if b == nil {
+		return big.NewInt(int64(0))
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[i] = b[len(b)-1-i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// linearCombination reads the factors of a linear combination into the row of the R1CS matrix
+func (rr *r1csReader) linearCombination(row []*big.Int) {
+	nFactors := int(rr.uint32())
+	for i := 0; i < nFactors && rr.err == nil; i++ {
+		wire := int(rr.uint32())
+		v := rr.le()
+		if rr.err != nil {
+			return
+		}
+		if wire >= len(row) || v.Cmp(r1csPrime) >= 0 {
+			rr.err = errors.New("r1cs factor out of the circuit")
+			return
+		}
+		row[wire] = new(big.Int).Mod(new(big.Int).Add(row[wire], v), r1csPrime)
+	}
+}
+
+// ReadR1CS reads a circuit in the iden3 .r1cs binary format (the one generated by circom), filling the R1CS matrices, the number of variables and the names of the public and private inputs. The wires are [one, outputs, public inputs, private inputs, ...], so the outputs are counted as public inputs. The signal names are the labels of the wires, which can be replaced by the circom names with ReadSym. The circuit has no flat code Constraints, so its witness must be computed outside
+func ReadR1CS(r io.Reader) (*Circuit, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 12 || !bytes.Equal(b[:4], r1csMagic) {
+		return nil, errors.New("not a r1cs file")
+	}
+	if binary.LittleEndian.Uint32(b[4:8]) != r1csVersion {
+		return nil, errors.New("r1cs version not supported")
+	}
+	nSections := binary.LittleEndian.Uint32(b[8:12])
+	file := &r1csReader{b: b[12:]}
+	sections := make(map[uint32][]byte)
+	for i := uint32(0); i < nSections && file.err == nil; i++ {
+		sType := file.uint32()
+		size := file.uint64()
+		if size > uint64(len(file.b)) {
+			return nil, errors.New("r1cs file too short")
+		}
+		if _, ok := sections[sType]; ok {
+			return nil, errors.New("duplicated r1cs section")
+		}
+		sections[sType] = file.read(int(size))
+	}
+	if file.err != nil {
+		return nil, file.err
+	}
+	for _, s := range []uint32{r1csSectionHeader, r1csSectionConstraints, r1csSectionWire2Label} {
+		if _, ok := sections[s]; !ok {
+			return nil, errors.New("missing r1cs section")
+		}
+	}
+
+	header := &r1csReader{b: sections[r1csSectionHeader]}
+	header.n8 = int(header.uint32())
+	if header.n8 != 32 || header.le().Cmp(r1csPrime) != 0 {
+		return nil, errors.New("r1cs field is not the bn128 scalar field")
+	}
+	nWires := int(header.uint32())
+	nPubOut := int(header.uint32())
+	nPubIn := int(header.uint32())
+	nPrvIn := int(header.uint32())
+	header.uint64() // nLabels
+	mConstraints := int(header.uint32())
+	if header.err != nil {
+		return nil, header.err
+	}
+	wire2Label := sections[r1csSectionWire2Label]
+	// each wire has a label of 8 bytes, and each constraint has at least the 12 bytes of the number of factors
+	if nWires < 1+nPubOut+nPubIn+nPrvIn || len(wire2Label) != 8*nWires || mConstraints > len(sections[r1csSectionConstraints])/12 {
+		return nil, errors.New("r1cs header not consistent with the sections")
+	}
+
+	circuit := &Circuit{}
+	circuit.NVars = nWires
+	circuit.NSignals = nWires
+	circuit.NPublic = nPubOut + nPubIn
+	circuit.Signals = []string{"one"}
+	for i := 1; i < nWires; i++ {
+		label := binary.LittleEndian.Uint64(wire2Label[8*i:])
+		circuit.Signals = append(circuit.Signals, "label"+strconv.FormatUint(label, 10))
+	}
+	circuit.PublicInputs = append([]string{}, circuit.Signals[1:1+circuit.NPublic]...)
+	circuit.PrivateInputs = append([]string{}, circuit.Signals[1+circuit.NPublic:1+circuit.NPublic+nPrvIn]...)
+
+	constraints := &r1csReader{b: sections[r1csSectionConstraints], n8: header.n8}
+	for i := 0; i < mConstraints && constraints.err == nil; i++ {
+		a := r1csqap.ArrayOfBigZeros(nWires)
+		b := r1csqap.ArrayOfBigZeros(nWires)
+		c := r1csqap.ArrayOfBigZeros(nWires)
+		constraints.linearCombination(a)
+		constraints.linearCombination(b)
+		constraints.linearCombination(c)
+		circuit.R1CS.A = append(circuit.R1CS.A, a)
+		circuit.R1CS.B = append(circuit.R1CS.B, b)
+		circuit.R1CS.C = append(circuit.R1CS.C, c)
+	}
+	if constraints.err != nil {
+		return nil, constraints.err
+	}
+	return circuit, nil
+}
+
+// ReadSym sets the signal names of a circuit read with ReadR1CS from the circom .sym file, which has a line "labelIndex,wireIndex,componentIndex,name" for each signal, with wireIndex -1 for the signals removed by the optimizations. If the file is not valid the circuit is not modified
+func (circ *Circuit) ReadSym(r io.Reader) error {
+	nPrivate := len(circ.PrivateInputs)
+	signals := append([]string{}, circ.Signals...)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, ",", 4)
+		if len(fields) != 4 {
+			return errors.New("invalid sym line: " + line)
+		}
+		wire, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return errors.New("invalid sym line: " + line)
+		}
+		if wire < 0 {
+			continue
+		}
+		if wire == 0 || wire >= len(signals) {
+			return errors.New("sym wire out of the circuit: " + line)
+		}
+		signals[wire] = fields[3]
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	circ.Signals = signals
+	circ.PublicInputs = append([]string{}, circ.Signals[1:1+circ.NPublic]...)
+	circ.PrivateInputs = append([]string{}, circ.Signals[1+circ.NPublic:1+circ.NPublic+nPrivate]...)
+	return nil
+}
@@ -0,0 +1,133 @@
+package circuitcompiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testR1CSFile returns the .r1cs file of the circuit with the wires [one, out, x, a, d], being out the output, x a public input, a a private input and d an internal signal:
+// d = x * a
+// out = (d - a) * 1
+func testR1CSFile(prime *big.Int) []byte {
+	le := func(v *big.Int) []byte {
+		b := make([]byte, 32)
+		v.FillBytes(b)
+		for i := 0; i < 16; i++ {
+			b[i], b[31-i] = b[31-i], b[i]
+		}
+		return b
+	}
+	u32 := func(buf *bytes.Buffer, v uint32) {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], v)
+		buf.Write(b[:])
+	}
+	u64 := func(buf *bytes.Buffer, v uint64) {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], v)
+		buf.Write(b[:])
+	}
+	lc := func(buf *bytes.Buffer, factors map[uint32]*big.Int) {
+		u32(buf, uint32(len(factors)))
+		for wire := uint32(0); wire < 5; wire++ {
+			if v, ok := factors[wire]; ok {
+				u32(buf, wire)
+				buf.Write(le(v))
+			}
+		}
+	}
+	one := big.NewInt(int64(1))
+	minusOne := new(big.Int).Sub(r1csPrime, one)
+
+	var header bytes.Buffer
+	u32(&header, 32)
+	header.Write(le(prime))
+	u32(&header, 5) // nWires
+	u32(&header, 1) // nPubOut
+	u32(&header, 1) // nPubIn
+	u32(&header, 1) // nPrvIn
+	u64(&header, 6) // nLabels
+	u32(&header, 2) // mConstraints
+
+	var constraints bytes.Buffer
+	lc(&constraints, map[uint32]*big.Int{2: one})
+	lc(&constraints, map[uint32]*big.Int{3: one})
+	lc(&constraints, map[uint32]*big.Int{4: one})
+	lc(&constraints, map[uint32]*big.Int{4: one, 3: minusOne})
+	lc(&constraints, map[uint32]*big.Int{0: one})
+	lc(&constraints, map[uint32]*big.Int{1: one})
+
+	var wire2Label bytes.Buffer
+	for _, label := range []uint64{0, 1, 2, 3, 5} {
+		u64(&wire2Label, label)
+	}
+
+	var file bytes.Buffer
+	file.Write(r1csMagic)
+	u32(&file, r1csVersion)
+	u32(&file, 3)
+	// the sections can be in any order
+	for _, s := range []struct {
+		t uint32
+		b bytes.Buffer
+	}{{r1csSectionConstraints, constraints}, {r1csSectionHeader, header}, {r1csSectionWire2Label, wire2Label}} {
+		u32(&file, s.t)
+		u64(&file, uint64(s.b.Len()))
+		file.Write(s.b.Bytes())
+	}
+	return file.Bytes()
+}
+
+func TestReadR1CS(t *testing.T) {
+	r1csFile := testR1CSFile(r1csPrime)
+	circuit, err := ReadR1CS(bytes.NewReader(r1csFile))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, circuit.NVars)
+	assert.Equal(t, 2, circuit.NPublic)
+	assert.Equal(t, []string{"one", "label1", "label2", "label3", "label5"}, circuit.Signals)
+	assert.Equal(t, []string{"label1", "label2"}, circuit.PublicInputs)
+	assert.Equal(t, []string{"label3"}, circuit.PrivateInputs)
+	assert.Equal(t, 2, len(circuit.R1CS.A))
+
+	// the witness satisfies the constraints
+	w := []*big.Int{big.NewInt(int64(1)), big.NewInt(int64(8)), big.NewInt(int64(3)), big.NewInt(int64(4)), big.NewInt(int64(12))}
+	dot := func(row []*big.Int) *big.Int {
+		r := big.NewInt(int64(0))
+		for i := range row {
+			r.Add(r, new(big.Int).Mul(row[i], w[i]))
+		}
+		return r.Mod(r, r1csPrime)
+	}
+	for i := range circuit.R1CS.A {
+		ab := new(big.Int).Mul(dot(circuit.R1CS.A[i]), dot(circuit.R1CS.B[i]))
+		assert.Equal(t, 0, ab.Mod(ab, r1csPrime).Cmp(dot(circuit.R1CS.C[i])))
+	}
+
+	sym := `1,1,0,main.out
+2,2,0,main.x
+3,3,0,main.a
+4,-1,0,main.removed
+5,4,0,main.d
+`
+	err = circuit.ReadSym(strings.NewReader(sym))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"one", "main.out", "main.x", "main.a", "main.d"}, circuit.Signals)
+	assert.Equal(t, []string{"main.out", "main.x"}, circuit.PublicInputs)
+	assert.Equal(t, []string{"main.a"}, circuit.PrivateInputs)
+	// an invalid line after a valid one does not change the names
+	err = circuit.ReadSym(strings.NewReader("1,1,0,main.y\n7,9,0,main.none\n"))
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"one", "main.out", "main.x", "main.a", "main.d"}, circuit.Signals)
+	assert.Equal(t, []string{"main.out", "main.x"}, circuit.PublicInputs)
+
+	// other field and truncated file
+	_, err = ReadR1CS(bytes.NewReader(testR1CSFile(big.NewInt(int64(7)))))
+	assert.NotNil(t, err)
+	_, err = ReadR1CS(bytes.NewReader(r1csFile[:len(r1csFile)-1]))
+	assert.NotNil(t, err)
+}
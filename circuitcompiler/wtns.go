@@ -0,0 +1,115 @@
+package circuitcompiler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+)
+
+// sections of the iden3 .wtns file
+const (
+	wtnsSectionHeader  = 1
+	wtnsSectionWitness = 2
+	wtnsVersion        = 2
+	wtnsFieldSize      = 32
+)
+
+var wtnsMagic = []byte("wtns")
+
+// ReadWtns reads a witness in the iden3 .wtns binary format, as the ones generated by the circom witness calculator
+func ReadWtns(r io.Reader) ([]*big.Int, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 12 || !bytes.Equal(b[:4], wtnsMagic) {
+		return nil, errors.New("not a wtns file")
+	}
+	version := binary.LittleEndian.Uint32(b[4:8])
+	if version != 1 && version != wtnsVersion {
+		return nil, errors.New("wtns version not supported")
+	}
+	nSections := binary.LittleEndian.Uint32(b[8:12])
+	file := &r1csReader{b: b[12:]}
+	sections := make(map[uint32][]byte)
+	for i := uint32(0); i < nSections && file.err == nil; i++ {
+		sType := file.uint32()
+		size := file.uint64()
+		if size > uint64(len(file.b)) {
+			return nil, errors.New("wtns file too short")
+		}
+		sections[sType] = file.read(int(size))
+	}
+	if file.err != nil {
+		return nil, file.err
+	}
+	if _, ok := sections[wtnsSectionHeader]; !ok {
+		return nil, errors.New("missing wtns section")
+	}
+	if _, ok := sections[wtnsSectionWitness]; !ok {
+		return nil, errors.New("missing wtns section")
+	}
+
+	header := &r1csReader{b: sections[wtnsSectionHeader]}
+	header.n8 = int(header.uint32())
+	if header.n8 != wtnsFieldSize || header.le().Cmp(r1csPrime) != 0 {
+		return nil, errors.New("wtns field is not the bn128 scalar field")
+	}
+	nWitness := int(header.uint32())
+	if header.err != nil {
+		return nil, header.err
+	}
+	values := &r1csReader{b: sections[wtnsSectionWitness], n8: header.n8}
+	if len(values.b) != nWitness*wtnsFieldSize {
+		return nil, errors.New("wtns header not consistent with the witness section")
+	}
+	var w []*big.Int
+	for i := 0; i < nWitness; i++ {
+		v := values.le()
+		if v.Cmp(r1csPrime) >= 0 {
+			return nil, errors.New("wtns value out of the field")
+		}
+		w = append(w, v)
+	}
+	return w, nil
+}
+
+// WriteWtns writes the witness in the iden3 .wtns binary format, with the values reduced to the bn128 scalar field
+func WriteWtns(w io.Writer, witness []*big.Int) error {
+	le := func(buf *bytes.Buffer, v *big.Int) {
+		var b [wtnsFieldSize]byte
+		v.FillBytes(b[:])
+		for i := wtnsFieldSize - 1; i >= 0; i-- {
+			buf.WriteByte(b[i])
+		}
+	}
+	u32 := func(buf *bytes.Buffer, v uint32) {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], v)
+		buf.Write(b[:])
+	}
+	section := func(buf *bytes.Buffer, sType uint32, size int) {
+		u32(buf, sType)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], uint64(size))
+		buf.Write(b[:])
+	}
+
+	var buf bytes.Buffer
+	buf.Write(wtnsMagic)
+	u32(&buf, wtnsVersion)
+	u32(&buf, 2)
+	section(&buf, wtnsSectionHeader, 4+wtnsFieldSize+4)
+	u32(&buf, wtnsFieldSize)
+	le(&buf, r1csPrime)
+	u32(&buf, uint32(len(witness)))
+	section(&buf, wtnsSectionWitness, len(witness)*wtnsFieldSize)
+	for _, v := range witness {
+		le(&buf, new(big.Int).Mod(v, r1csPrime))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,50 @@
+package circuitcompiler
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/arnaucube/go-snark-study/r1csqap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWtns(t *testing.T) {
+	flat := `
+	func main(private s0, public s1):
+		s2 = s0 * s0
+		s3 = s2 * s0
+		s4 = s3 + s0
+		s5 = s4 + 5
+		equals(s1, s5)
+		out = 1 * 1
+	`
+	parser := NewParser(strings.NewReader(flat))
+	circuit, err := parser.Parse()
+	assert.Nil(t, err)
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(35))})
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	err = WriteWtns(&buf, w)
+	assert.Nil(t, err)
+	wtns := append([]byte{}, buf.Bytes()...)
+	assert.Equal(t, 12+12+40+12+32*len(w), len(wtns))
+	readW, err := ReadWtns(bytes.NewReader(wtns))
+	assert.Nil(t, err)
+	assert.True(t, r1csqap.BigArraysEqual(w, readW))
+
+	// negative values are stored reduced to the field
+	buf.Reset()
+	err = WriteWtns(&buf, []*big.Int{big.NewInt(int64(1)), big.NewInt(int64(-1))})
+	assert.Nil(t, err)
+	readW, err = ReadWtns(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, readW[1].Cmp(new(big.Int).Sub(r1csPrime, big.NewInt(int64(1)))))
+
+	_, err = ReadWtns(bytes.NewReader(wtns[:len(wtns)-1]))
+	assert.NotNil(t, err)
+	_, err = ReadWtns(bytes.NewReader(append([]byte("wtnz"), wtns[4:]...)))
+	assert.NotNil(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/big"
@@ -13,6 +14,7 @@ import (
 
 	snark "github.com/arnaucube/go-snark-study"
 	"github.com/arnaucube/go-snark-study/circuitcompiler"
+	"github.com/arnaucube/go-snark-study/externalVerif"
 	"github.com/arnaucube/go-snark-study/groth16"
 	"github.com/arnaucube/go-snark-study/r1csqap"
 	"github.com/arnaucube/go-snark-study/utils"
@@ -73,6 +75,36 @@ var commands = []cli.Command{
 				Usage:   "verify the snark proofs",
 				Action:  Groth16VerifyProofs,
 			},
+			{
+				Name:    "exportcircom",
+				Aliases: []string{},
+				Usage:   "export the verification key, the proofs and the public inputs in the snarkjs json formats",
+				Action:  Groth16ExportCircom,
+			},
+			{
+				Name:    "exportzkey",
+				Aliases: []string{},
+				Usage:   "export the trusted setup in the snarkjs zkey format",
+				Action:  Groth16ExportZkey,
+			},
+			{
+				Name:    "importzkey",
+				Aliases: []string{},
+				Usage:   "import the trusted setup and the circuit R1CS from a snarkjs zkey file",
+				Action:  Groth16ImportZkey,
+			},
+			{
+				Name:    "exportsolidity",
+				Aliases: []string{"export-solidity"},
+				Usage:   "export the Solidity verifier contract of the verification key",
+				Action:  Groth16ExportSolidity,
+			},
+			{
+				Name:    "soliditycalldata",
+				Aliases: []string{},
+				Usage:   "print the proofs and public inputs as the calldata of the Solidity verifier",
+				Action:  Groth16SolidityCalldata,
+			},
 		},
 	},
 }
@@ -92,6 +124,84 @@ func main() {
 	}
 }
 
+// storeBinary creates the file and writes into it the data of the write function
+func storeBinary(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if err = write(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// loadBinary opens the file and passes a buffered reader of it to the read function
+func loadBinary(path string, read func(r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return read(bufio.NewReader(f))
+}
+
+// loadTrustedSetup reads the trustedsetup.bin file, telling to run the trusted setup again if there is only the trustedsetup.json of the previous versions
+func loadTrustedSetup(read func(r io.Reader) error) error {
+	err := loadBinary("trustedsetup.bin", read)
+	if os.IsNotExist(err) {
+		if _, errJSON := os.Stat("trustedsetup.json"); errJSON == nil {
+			return errors.New("trustedsetup.bin not found, trustedsetup.json is the old format of the trusted setup, run the trustedsetup command again")
+		}
+	}
+	return err
+}
+
+// loadWitness reads the witness from the .wtns file if the path is given, or calculates it from the privateInputs.json and publicInputs.json files
+func loadWitness(path string, circuit circuitcompiler.Circuit) ([]*big.Int, error) {
+	if path != "" {
+		var w []*big.Int
+		err := loadBinary(path, func(r io.Reader) error {
+			var err error
+			w, err = circuitcompiler.ReadWtns(r)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(w) < circuit.NVars {
+			return nil, errors.New("witness shorter than the circuit variables")
+		}
+		return w, nil
+	}
+
+	// read privateInputs file
+	privateInputsFile, err := ioutil.ReadFile("privateInputs.json")
+	if err != nil {
+		return nil, err
+	}
+	// read publicInputs file
+	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
+	if err != nil {
+		return nil, err
+	}
+	// parse inputs from inputsFile
+	var inputs circuitcompiler.Inputs
+	err = json.Unmarshal([]byte(string(privateInputsFile)), &inputs.Private)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal([]byte(string(publicInputsFile)), &inputs.Public)
+	if err != nil {
+		return nil, err
+	}
+
+	// calculate wittness
+	return circuit.CalculateWitness(inputs.Private, inputs.Public)
+}
+
 func CompileCircuit(context *cli.Context) error {
 	fmt.Println("cli")
 
@@ -130,6 +240,11 @@ func CompileCircuit(context *cli.Context) error {
 	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
 	panicErr(err)
 	fmt.Println("\nwitness", w)
+	err = storeBinary("witness.wtns", func(wr io.Writer) error {
+		return circuitcompiler.WriteWtns(wr, w)
+	})
+	panicErr(err)
+	fmt.Println("Witness written to witness.wtns")
 
 	// flat code to R1CS
 	fmt.Println("\ngenerating R1CS from flat code")
@@ -140,7 +255,8 @@ func CompileCircuit(context *cli.Context) error {
 	fmt.Println("c:", c)
 
 	// R1CS to QAP
-	alphas, betas, gammas, zx := snark.Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, zx, err := snark.Utils.PF.R1CSToQAP(a, b, c)
+	panicErr(err)
 	fmt.Println("qap")
 	fmt.Println(alphas)
 	fmt.Println(betas)
@@ -203,28 +319,6 @@ func CompileCircuit(context *cli.Context) error {
 		jsonFile.Close()
 	}
 
-	// store px
-	jsonData, err = json.Marshal(px)
-	panicErr(err)
-	// store setup into file
-	jsonFile, err = os.Create("px.json")
-	panicErr(err)
-	defer jsonFile.Close()
-	jsonFile.Write(jsonData)
-	jsonFile.Close()
-	fmt.Println("Px data written to ", jsonFile.Name())
-	if wasmFlag {
-		pxString := utils.ArrayBigIntToString(px)
-		jsonData, err = json.Marshal(pxString)
-		panicErr(err)
-		// store setup into file
-		jsonFile, err = os.Create("pxString.json")
-		panicErr(err)
-		defer jsonFile.Close()
-		jsonFile.Write(jsonData)
-		jsonFile.Close()
-	}
-
 	return nil
 }
 
@@ -259,7 +353,8 @@ func TrustedSetup(context *cli.Context) error {
 	panicErr(err)
 
 	// R1CS to QAP
-	alphas, betas, gammas, _ := snark.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	alphas, betas, gammas, _, err := snark.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	panicErr(err)
 	fmt.Println("qap")
 	fmt.Println(alphas)
 	fmt.Println(betas)
@@ -276,16 +371,12 @@ func TrustedSetup(context *cli.Context) error {
 	tsetup.Vk = setup.Vk
 	tsetup.Pk.G1T = setup.Pk.G1T
 
-	// store setup to json
-	jsonData, err := json.Marshal(tsetup)
+	// store setup into the binary key file
+	err = storeBinary("trustedsetup.bin", func(w io.Writer) error {
+		return utils.WriteSetupBinary(w, tsetup)
+	})
 	panicErr(err)
-	// store setup into file
-	jsonFile, err := os.Create("trustedsetup.json")
-	panicErr(err)
-	defer jsonFile.Close()
-	jsonFile.Write(jsonData)
-	jsonFile.Close()
-	fmt.Println("Trusted Setup data written to ", jsonFile.Name())
+	fmt.Println("Trusted Setup data written to trustedsetup.bin")
 	if wasmFlag {
 		tsetupString := utils.SetupToString(tsetup)
 		jsonData, err := json.Marshal(tsetupString)
@@ -308,45 +399,25 @@ func GenerateProofs(context *cli.Context) error {
 	json.Unmarshal([]byte(string(compiledcircuitFile)), &circuit)
 	panicErr(err)
 
-	// open trustedsetup.json
-	trustedsetupFile, err := ioutil.ReadFile("trustedsetup.json")
-	panicErr(err)
+	// read the trusted setup from the binary key file, decoding it while it is read
 	var trustedsetup snark.Setup
-	json.Unmarshal([]byte(string(trustedsetupFile)), &trustedsetup)
+	err = loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		trustedsetup, err = utils.ReadSetupBinary(r)
+		return err
+	})
 	panicErr(err)
 
-	// read privateInputs file
-	privateInputsFile, err := ioutil.ReadFile("privateInputs.json")
-	panicErr(err)
-	// read publicInputs file
-	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
-	panicErr(err)
-	// parse inputs from inputsFile
-	var inputs circuitcompiler.Inputs
-	err = json.Unmarshal([]byte(string(privateInputsFile)), &inputs.Private)
-	panicErr(err)
-	err = json.Unmarshal([]byte(string(publicInputsFile)), &inputs.Public)
-	panicErr(err)
-
-	// calculate wittness
-	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
+	// read the witness file, or calculate the witness from the inputs files
+	w, err := loadWitness(context.Args().Get(0), circuit)
 	panicErr(err)
 	fmt.Println("witness", w)
 
-	// flat code to R1CS
-	a := circuit.R1CS.A
-	b := circuit.R1CS.B
-	c := circuit.R1CS.C
-	// R1CS to QAP
-	alphas, betas, gammas, _ := snark.Utils.PF.R1CSToQAP(a, b, c)
-	_, _, _, px := snark.Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	hx := snark.Utils.PF.DivisorPolynomial(px, trustedsetup.Pk.Z)
-
 	fmt.Println(circuit)
 	fmt.Println(trustedsetup.Pk.G1T)
-	fmt.Println(hx)
 	fmt.Println(w)
-	proof, err := snark.GenerateProofs(circuit, trustedsetup.Pk, w, px)
+	// h(x) is computed by the prover from the circuit R1CS and the witness
+	proof, err := snark.GenerateProofs(circuit, trustedsetup.Pk, w)
 	panicErr(err)
 
 	fmt.Println("\n proofs:")
@@ -370,24 +441,28 @@ func VerifyProofs(context *cli.Context) error {
 	proofsFile, err := ioutil.ReadFile("proofs.json")
 	panicErr(err)
 	var proof snark.Proof
-	json.Unmarshal([]byte(string(proofsFile)), &proof)
+	err = json.Unmarshal([]byte(string(proofsFile)), &proof)
 	panicErr(err)
-
-	// open trustedsetup.json
-	trustedsetupFile, err := ioutil.ReadFile("trustedsetup.json")
+	err = snark.ValidateProof(proof)
 	panicErr(err)
-	var trustedsetup snark.Setup
-	json.Unmarshal([]byte(string(trustedsetupFile)), &trustedsetup)
+
+	// read the Vk from the binary key file, without reading the Pk
+	var vk snark.Vk
+	err = loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		vk, err = utils.ReadVkBinary(r)
+		return err
+	})
 	panicErr(err)
 
 	// read publicInputs file
 	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
 	panicErr(err)
-	var publicSignals []*big.Int
+	var publicSignals circuitcompiler.InputValues
 	err = json.Unmarshal([]byte(string(publicInputsFile)), &publicSignals)
 	panicErr(err)
 
-	verified := snark.VerifyProof(trustedsetup.Vk, proof, publicSignals, true)
+	verified := snark.VerifyProof(vk, proof, publicSignals, true)
 	if !verified {
 		fmt.Println("ERROR: proofs not verified")
 	} else {
@@ -423,7 +498,8 @@ func Groth16TrustedSetup(context *cli.Context) error {
 	panicErr(err)
 
 	// R1CS to QAP
-	alphas, betas, gammas, _ := snark.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	alphas, betas, gammas, _, err := snark.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	panicErr(err)
 	fmt.Println("qap")
 	fmt.Println(alphas)
 	fmt.Println(betas)
@@ -439,16 +515,12 @@ func Groth16TrustedSetup(context *cli.Context) error {
 	tsetup.Pk = setup.Pk
 	tsetup.Vk = setup.Vk
 
-	// store setup to json
-	jsonData, err := json.Marshal(tsetup)
-	panicErr(err)
-	// store setup into file
-	jsonFile, err := os.Create("trustedsetup.json")
+	// store setup into the binary key file
+	err = storeBinary("trustedsetup.bin", func(w io.Writer) error {
+		return utils.WriteGrothSetupBinary(w, tsetup)
+	})
 	panicErr(err)
-	defer jsonFile.Close()
-	jsonFile.Write(jsonData)
-	jsonFile.Close()
-	fmt.Println("Trusted Setup data written to ", jsonFile.Name())
+	fmt.Println("Trusted Setup data written to trustedsetup.bin")
 	return nil
 }
 
@@ -460,45 +532,25 @@ func Groth16GenerateProofs(context *cli.Context) error {
 	json.Unmarshal([]byte(string(compiledcircuitFile)), &circuit)
 	panicErr(err)
 
-	// open trustedsetup.json
-	trustedsetupFile, err := ioutil.ReadFile("trustedsetup.json")
-	panicErr(err)
+	// read the trusted setup from the binary key file, decoding it while it is read
 	var trustedsetup groth16.Setup
-	json.Unmarshal([]byte(string(trustedsetupFile)), &trustedsetup)
-	panicErr(err)
-
-	// read privateInputs file
-	privateInputsFile, err := ioutil.ReadFile("privateInputs.json")
-	panicErr(err)
-	// read publicInputs file
-	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
-	panicErr(err)
-	// parse inputs from inputsFile
-	var inputs circuitcompiler.Inputs
-	err = json.Unmarshal([]byte(string(privateInputsFile)), &inputs.Private)
-	panicErr(err)
-	err = json.Unmarshal([]byte(string(publicInputsFile)), &inputs.Public)
+	err = loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		trustedsetup, err = utils.ReadGrothSetupBinary(r)
+		return err
+	})
 	panicErr(err)
 
-	// calculate wittness
-	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
+	// read the witness file, or calculate the witness from the inputs files
+	w, err := loadWitness(context.Args().Get(0), circuit)
 	panicErr(err)
 	fmt.Println("witness", w)
 
-	// flat code to R1CS
-	a := circuit.R1CS.A
-	b := circuit.R1CS.B
-	c := circuit.R1CS.C
-	// R1CS to QAP
-	alphas, betas, gammas, _ := groth16.Utils.PF.R1CSToQAP(a, b, c)
-	_, _, _, px := groth16.Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	hx := groth16.Utils.PF.DivisorPolynomial(px, trustedsetup.Pk.Z)
-
 	fmt.Println(circuit)
 	fmt.Println(trustedsetup.Pk.PowersTauDelta)
-	fmt.Println(hx)
 	fmt.Println(w)
-	proof, err := groth16.GenerateProofs(circuit, trustedsetup.Pk, w, px)
+	// h(x) is computed by the prover from the circuit R1CS and the witness
+	proof, err := groth16.GenerateProofs(circuit, trustedsetup.Pk, w)
 	panicErr(err)
 
 	fmt.Println("\n proofs:")
@@ -522,24 +574,28 @@ func Groth16VerifyProofs(context *cli.Context) error {
 	proofsFile, err := ioutil.ReadFile("proofs.json")
 	panicErr(err)
 	var proof groth16.Proof
-	json.Unmarshal([]byte(string(proofsFile)), &proof)
+	err = json.Unmarshal([]byte(string(proofsFile)), &proof)
 	panicErr(err)
-
-	// open trustedsetup.json
-	trustedsetupFile, err := ioutil.ReadFile("trustedsetup.json")
+	err = groth16.ValidateProof(proof)
 	panicErr(err)
-	var trustedsetup groth16.Setup
-	json.Unmarshal([]byte(string(trustedsetupFile)), &trustedsetup)
+
+	// read the Vk from the binary key file, without reading the Pk
+	var vk groth16.Vk
+	err = loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		vk, err = utils.ReadGrothVkBinary(r)
+		return err
+	})
 	panicErr(err)
 
 	// read publicInputs file
 	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
 	panicErr(err)
-	var publicSignals []*big.Int
+	var publicSignals circuitcompiler.InputValues
 	err = json.Unmarshal([]byte(string(publicInputsFile)), &publicSignals)
 	panicErr(err)
 
-	verified := groth16.VerifyProof(trustedsetup.Vk, proof, publicSignals, true)
+	verified := groth16.VerifyProof(vk, proof, publicSignals, true)
 	if !verified {
 		fmt.Println("ERROR: proofs not verified")
 	} else {
@@ -547,3 +603,125 @@ func Groth16VerifyProofs(context *cli.Context) error {
 	}
 	return nil
 }
+
+func Groth16ExportCircom(context *cli.Context) error {
+	// read the Vk from the binary key file
+	var vk groth16.Vk
+	err := loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		vk, err = utils.ReadGrothVkBinary(r)
+		return err
+	})
+	panicErr(err)
+
+	// open proofs.json
+	proofsFile, err := ioutil.ReadFile("proofs.json")
+	panicErr(err)
+	var proof groth16.Proof
+	err = json.Unmarshal([]byte(string(proofsFile)), &proof)
+	panicErr(err)
+
+	// read publicInputs file
+	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
+	panicErr(err)
+	var publicSignals circuitcompiler.InputValues
+	err = json.Unmarshal([]byte(string(publicInputsFile)), &publicSignals)
+	panicErr(err)
+
+	err = externalVerif.ExportToCircom(".", vk, proof, publicSignals)
+	panicErr(err)
+	fmt.Println("snarkjs data written to verification_key.json, proof.json and public.json")
+	return nil
+}
+
+func Groth16ExportZkey(context *cli.Context) error {
+	// open compiledcircuit.json
+	compiledcircuitFile, err := ioutil.ReadFile("compiledcircuit.json")
+	panicErr(err)
+	var circuit circuitcompiler.Circuit
+	err = json.Unmarshal([]byte(string(compiledcircuitFile)), &circuit)
+	panicErr(err)
+
+	// read the trusted setup from the binary key file
+	var trustedsetup groth16.Setup
+	err = loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		trustedsetup, err = utils.ReadGrothSetupBinary(r)
+		return err
+	})
+	panicErr(err)
+
+	err = storeBinary("circuit.zkey", func(w io.Writer) error {
+		return externalVerif.ExportZkey(w, trustedsetup, circuit)
+	})
+	panicErr(err)
+	fmt.Println("Trusted Setup data written to circuit.zkey")
+	return nil
+}
+
+func Groth16ImportZkey(context *cli.Context) error {
+	path := context.Args().Get(0)
+	if path == "" {
+		path = "circuit.zkey"
+	}
+	// read the trusted setup and the circuit R1CS from the zkey file
+	var trustedsetup groth16.Setup
+	var circuit circuitcompiler.Circuit
+	err := loadBinary(path, func(r io.Reader) error {
+		var err error
+		trustedsetup, circuit, err = externalVerif.ImportZkey(r)
+		return err
+	})
+	panicErr(err)
+
+	err = storeBinary("trustedsetup.bin", func(w io.Writer) error {
+		return utils.WriteGrothSetupBinary(w, trustedsetup)
+	})
+	panicErr(err)
+	fmt.Println("Trusted Setup data written to trustedsetup.bin")
+
+	// store the circuit to json, which has only the R1CS, so its witness is given to genproofs as a .wtns file
+	jsonData, err := json.Marshal(circuit)
+	panicErr(err)
+	err = ioutil.WriteFile("compiledcircuit.json", jsonData, 0644)
+	panicErr(err)
+	fmt.Println("Circuit R1CS written to compiledcircuit.json")
+	return nil
+}
+
+func Groth16ExportSolidity(context *cli.Context) error {
+	// read the Vk from the binary key file
+	var vk groth16.Vk
+	err := loadTrustedSetup(func(r io.Reader) error {
+		var err error
+		vk, err = utils.ReadGrothVkBinary(r)
+		return err
+	})
+	panicErr(err)
+
+	verifier, err := externalVerif.SolidityVerifier(vk)
+	panicErr(err)
+	err = ioutil.WriteFile("verifier.sol", []byte(verifier), 0644)
+	panicErr(err)
+	fmt.Println("Solidity verifier written to verifier.sol")
+	return nil
+}
+
+func Groth16SolidityCalldata(context *cli.Context) error {
+	// open proofs.json
+	proofsFile, err := ioutil.ReadFile("proofs.json")
+	panicErr(err)
+	var proof groth16.Proof
+	err = json.Unmarshal([]byte(string(proofsFile)), &proof)
+	panicErr(err)
+
+	// read publicInputs file
+	publicInputsFile, err := ioutil.ReadFile("publicInputs.json")
+	panicErr(err)
+	var publicSignals circuitcompiler.InputValues
+	err = json.Unmarshal([]byte(string(publicInputsFile)), &publicSignals)
+	panicErr(err)
+
+	fmt.Println(externalVerif.SolidityCalldata(proof, publicSignals))
+	return nil
+}
@@ -0,0 +1,77 @@
+package externalVerif
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+
+	"github.com/arnaucube/go-snark-study/groth16"
+	"github.com/arnaucube/go-snark-study/utils"
+)
+
+// g1ToCircom returns the point in the snarkjs format, the affine coordinates with z = 1, or [0, 1, 0] for the zero point
+func g1ToCircom(p [3]*big.Int) [3]string {
+	if groth16.Utils.Bn.G1.IsZero(p) {
+		return [3]string{"0", "1", "0"}
+	}
+	a := groth16.Utils.Bn.G1.Affine(p)
+	return [3]string{a[0].String(), a[1].String(), "1"}
+}
+
+// g2ToCircom returns the point in the snarkjs format, the affine coordinates with z = 1, or [0, 1, 0] for the zero point
+func g2ToCircom(p [3][2]*big.Int) [3][2]string {
+	if groth16.Utils.Bn.G2.IsZero(p) {
+		return [3][2]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}
+	}
+	return utils.BigInt32ToString(groth16.Utils.Bn.G2.Affine(p))
+}
+
+// VkToCircom returns the Vk in the snarkjs verification_key.json format, with e(alpha, beta) in vk_alphabeta_12
+func VkToCircom(vk groth16.Vk) CircomVk {
+	var circomVk CircomVk
+	circomVk.Protocol = "groth16"
+	circomVk.Curve = "bn128"
+	circomVk.NPublic = len(vk.IC) - 1
+	for _, ic := range vk.IC {
+		circomVk.IC = append(circomVk.IC, g1ToCircom(ic))
+	}
+	circomVk.Alpha1 = g1ToCircom(vk.G1.Alpha)
+	circomVk.Beta2 = g2ToCircom(vk.G2.Beta)
+	circomVk.Gamma2 = g2ToCircom(vk.G2.Gamma)
+	circomVk.Delta2 = g2ToCircom(vk.G2.Delta)
+	alphaBeta := groth16.Utils.Bn.Fq12.Affine(groth16.Utils.Bn.Pairing(vk.G1.Alpha, vk.G2.Beta))
+	circomVk.AlphaBeta12 = utils.BigInt232ToString(alphaBeta)
+	return circomVk
+}
+
+// ProofToCircom returns the Proof in the snarkjs proof.json format
+func ProofToCircom(proof groth16.Proof) CircomProof {
+	return CircomProof{
+		PiA:      g1ToCircom(proof.PiA),
+		PiB:      g2ToCircom(proof.PiB),
+		PiC:      g1ToCircom(proof.PiC),
+		Protocol: "groth16",
+		Curve:    "bn128",
+	}
+}
+
+// writeJSON stores the value as indented json in the file
+func writeJSON(path string, v interface{}) error {
+	jsonData, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, jsonData, 0644)
+}
+
+// ExportToCircom writes the verification_key.json, proof.json and public.json files in the snarkjs format into the dir
+func ExportToCircom(dir string, vk groth16.Vk, proof groth16.Proof, publicSignals []*big.Int) error {
+	if err := writeJSON(filepath.Join(dir, "verification_key.json"), VkToCircom(vk)); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(dir, "proof.json"), ProofToCircom(proof)); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(dir, "public.json"), utils.ArrayBigIntToString(publicSignals))
+}
@@ -2,6 +2,7 @@ package externalVerif
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 
@@ -10,17 +11,54 @@ import (
 )
 
 type CircomProof struct {
-	PiA [3]string    `json:"pi_a"`
-	PiB [3][2]string `json:"pi_b"`
-	PiC [3]string    `json:"pi_c"`
+	PiA      [3]string    `json:"pi_a"`
+	PiB      [3][2]string `json:"pi_b"`
+	PiC      [3]string    `json:"pi_c"`
+	Protocol string       `json:"protocol,omitempty"`
+	Curve    string       `json:"curve,omitempty"`
 }
 type CircomVk struct {
+	Protocol    string          `json:"protocol,omitempty"`
+	Curve       string          `json:"curve,omitempty"`
+	NPublic     int             `json:"nPublic"`
 	IC          [][3]string     `json:"IC"`
-	Alpha1      [3]string       `json:"vk_alfa_1"`
+	Alpha1      [3]string       `json:"vk_alpha_1"`
 	Beta2       [3][2]string    `json:"vk_beta_2"`
 	Gamma2      [3][2]string    `json:"vk_gamma_2"`
 	Delta2      [3][2]string    `json:"vk_delta_2"`
-	AlphaBeta12 [2][3][2]string `json:"vk_alpfabeta_12"` // not really used, for the moment in go-snarks calculed in verification time
+	AlphaBeta12 [2][3][2]string `json:"vk_alphabeta_12"` // e(alpha, beta), checked against the one of Alpha1 and Beta2
+}
+
+// UnmarshalJSON decodes the verification key, accepting also the names vk_alfa_1 and vk_alfabeta_12 of the older versions of circom/snarkjs
+func (circomVk *CircomVk) UnmarshalJSON(data []byte) error {
+	type circomVkFields CircomVk
+	var v struct {
+		circomVkFields
+		Alfa1      [3]string       `json:"vk_alfa_1"`
+		AlfaBeta12 [2][3][2]string `json:"vk_alfabeta_12"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*circomVk = CircomVk(v.circomVkFields)
+	if circomVk.Alpha1[0] == "" {
+		circomVk.Alpha1 = v.Alfa1
+	}
+	if circomVk.AlphaBeta12[0][0][0] == "" {
+		circomVk.AlphaBeta12 = v.AlfaBeta12
+	}
+	return nil
+}
+
+// CircomVkToVk parses the points of the circom verification key into a groth16.Vk
+func CircomVkToVk(circomVk CircomVk) (groth16.Vk, error) {
+	var strVk utils.GrothVkString
+	strVk.IC = circomVk.IC
+	strVk.G1.Alpha = circomVk.Alpha1
+	strVk.G2.Beta = circomVk.Beta2
+	strVk.G2.Gamma = circomVk.Gamma2
+	strVk.G2.Delta = circomVk.Delta2
+	return utils.GrothVkFromString(strVk)
 }
 
 func VerifyFromCircom(vkPath, proofPath, publicSignalsPath string) (bool, error) {
@@ -30,18 +68,12 @@ func VerifyFromCircom(vkPath, proofPath, publicSignalsPath string) (bool, error)
 		return false, err
 	}
 	var circomVk CircomVk
-	json.Unmarshal([]byte(string(vkFile)), &circomVk)
+	err = json.Unmarshal([]byte(string(vkFile)), &circomVk)
 	if err != nil {
 		return false, err
 	}
 
-	var strVk utils.GrothVkString
-	strVk.IC = circomVk.IC
-	strVk.G1.Alpha = circomVk.Alpha1
-	strVk.G2.Beta = circomVk.Beta2
-	strVk.G2.Gamma = circomVk.Gamma2
-	strVk.G2.Delta = circomVk.Delta2
-	vk, err := utils.GrothVkFromString(strVk)
+	vk, err := CircomVkToVk(circomVk)
 	if err != nil {
 		return false, err
 	}
@@ -53,7 +85,7 @@ func VerifyFromCircom(vkPath, proofPath, publicSignalsPath string) (bool, error)
 		return false, err
 	}
 	var circomProof CircomProof
-	json.Unmarshal([]byte(string(proofsFile)), &circomProof)
+	err = json.Unmarshal([]byte(string(proofsFile)), &circomProof)
 	if err != nil {
 		return false, err
 	}
@@ -75,7 +107,7 @@ func VerifyFromCircom(vkPath, proofPath, publicSignalsPath string) (bool, error)
 		return false, err
 	}
 	var publicStr []string
-	json.Unmarshal([]byte(string(publicFile)), &publicStr)
+	err = json.Unmarshal([]byte(string(publicFile)), &publicStr)
 	if err != nil {
 		return false, err
 	}
@@ -85,6 +117,26 @@ func VerifyFromCircom(vkPath, proofPath, publicSignalsPath string) (bool, error)
 	}
 	fmt.Println("publicSignals parsed:", publicSignals)
 
-	verified := groth16.VerifyProof(vk, proof, publicSignals, true)
+	pvk, err := prepareCircomVk(vk, circomVk)
+	if err != nil {
+		return false, err
+	}
+	verified := groth16.VerifyProofPrepared(pvk, proof, publicSignals, true)
 	return verified, nil
 }
+
+// prepareCircomVk returns the PreparedVk of the Vk. The e(alpha, beta) value of the circom verification key, when it is provided, must be the one of its alpha and beta, as a different value would change what the proofs verify
+func prepareCircomVk(vk groth16.Vk, circomVk CircomVk) (groth16.PreparedVk, error) {
+	pvk := groth16.PrepareVk(vk)
+	if circomVk.AlphaBeta12[0][0][0] == "" {
+		return pvk, nil
+	}
+	alphaBeta, err := utils.String232ToBigInt(circomVk.AlphaBeta12)
+	if err != nil {
+		return groth16.PreparedVk{}, err
+	}
+	if !groth16.Utils.Bn.Fq12.Equal(alphaBeta, pvk.AlphaBeta) {
+		return groth16.PreparedVk{}, errors.New("vk_alphabeta_12 is not e(vk_alpha_1, vk_beta_2)")
+	}
+	return pvk, nil
+}
@@ -1,8 +1,13 @@
 package externalVerif
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/arnaucube/go-snark-study/groth16"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,3 +16,58 @@ func TestVerifyFromCircom(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, verified)
 }
+
+func TestPrepareCircomVk(t *testing.T) {
+	vkFile, err := ioutil.ReadFile("circom-test/verification_key.json")
+	assert.Nil(t, err)
+	var circomVk CircomVk
+	err = json.Unmarshal(vkFile, &circomVk)
+	assert.Nil(t, err)
+	// the file uses the names vk_alfa_1 and vk_alfabeta_12 of the older versions
+	assert.NotEqual(t, "", circomVk.Alpha1[0])
+	assert.NotEqual(t, "", circomVk.AlphaBeta12[0][0][0])
+
+	vk, err := CircomVkToVk(circomVk)
+	assert.Nil(t, err)
+
+	// the vk_alfabeta_12 from circom is e(alpha, beta)
+	pvk, err := prepareCircomVk(vk, circomVk)
+	assert.Nil(t, err)
+	assert.True(t, groth16.Utils.Bn.Fq12.Equal(groth16.Utils.Bn.Pairing(vk.G1.Alpha, vk.G2.Beta), pvk.AlphaBeta))
+
+	// a vk_alfabeta_12 that is not e(alpha, beta) is rejected
+	circomVk.AlphaBeta12[0][0][0] = "1"
+	_, err = prepareCircomVk(vk, circomVk)
+	assert.EqualError(t, err, "vk_alphabeta_12 is not e(vk_alpha_1, vk_beta_2)")
+}
+
+func TestExportToCircom(t *testing.T) {
+	circuit, setup, w, publicSignals := testGrothSetup(t)
+	proof, err := groth16.GenerateProofs(*circuit, setup.Pk, w)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "circom-export")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	err = ExportToCircom(dir, setup.Vk, proof, publicSignals)
+	assert.Nil(t, err)
+
+	verified, err := VerifyFromCircom(filepath.Join(dir, "verification_key.json"), filepath.Join(dir, "proof.json"), filepath.Join(dir, "public.json"))
+	assert.Nil(t, err)
+	assert.True(t, verified)
+
+	// the exported vk_alphabeta_12 is the one that circom would give
+	circomVk := VkToCircom(setup.Vk)
+	assert.Equal(t, 1, circomVk.NPublic)
+	jsonData, err := json.Marshal(circomVk)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(jsonData), "vk_alfa")
+	var decoded CircomVk
+	assert.Nil(t, json.Unmarshal(jsonData, &decoded))
+	assert.Equal(t, circomVk, decoded)
+	vk, err := CircomVkToVk(circomVk)
+	assert.Nil(t, err)
+	pvk, err := prepareCircomVk(vk, circomVk)
+	assert.Nil(t, err)
+	assert.True(t, groth16.Utils.Bn.Fq12.Equal(groth16.Utils.Bn.Pairing(vk.G1.Alpha, vk.G2.Beta), pvk.AlphaBeta))
+}
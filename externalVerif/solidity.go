@@ -0,0 +1,217 @@
+package externalVerif
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+
+	"github.com/arnaucube/go-snark-study/groth16"
+)
+
+// solidityG1 is a G1 point in affine coordinates, as used by the EIP-197 precompiles
+type solidityG1 struct {
+	X, Y string
+}
+
+// solidityG2 is a G2 point in affine coordinates, with each coordinate in the [imaginary, real] order that the EIP-197 precompiles expect
+type solidityG2 struct {
+	X1, X0, Y1, Y0 string
+}
+
+func g1ToSolidity(p [3]*big.Int) solidityG1 {
+	if groth16.Utils.Bn.G1.IsZero(p) {
+		return solidityG1{"0", "0"}
+	}
+	a := groth16.Utils.Bn.G1.Affine(p)
+	return solidityG1{a[0].String(), a[1].String()}
+}
+
+func g2ToSolidity(p [3][2]*big.Int) solidityG2 {
+	if groth16.Utils.Bn.G2.IsZero(p) {
+		return solidityG2{"0", "0", "0", "0"}
+	}
+	a := groth16.Utils.Bn.G2.Affine(p)
+	return solidityG2{a[0][1].String(), a[0][0].String(), a[1][1].String(), a[1][0].String()}
+}
+
+var solidityVerifierTemplate = template.Must(template.New("verifier").Parse(`// SPDX-License-Identifier: GPL-3.0
+// Groth16 verifier generated by go-snark-study
+pragma solidity ^0.8.0;
+
+library Pairing {
+    uint256 constant PRIME_Q = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    struct G1Point {
+        uint256 X;
+        uint256 Y;
+    }
+
+    // the coordinates are encoded as [imaginary, real]
+    struct G2Point {
+        uint256[2] X;
+        uint256[2] Y;
+    }
+
+    // the point at infinity is (0, 0), and it is its own negation
+    function negate(G1Point memory p) internal pure returns (G1Point memory) {
+        if (p.Y % PRIME_Q == 0) {
+            return G1Point(0, 0);
+        }
+        return G1Point(p.X, PRIME_Q - (p.Y % PRIME_Q));
+    }
+
+    // addition uses the EIP-196 ecAdd precompile
+    function addition(G1Point memory p1, G1Point memory p2) internal view returns (G1Point memory r) {
+        uint256[4] memory input = [p1.X, p1.Y, p2.X, p2.Y];
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 6, input, 0x80, r, 0x40)
+        }
+        require(success, "pairing-add-failed");
+    }
+
+    // scalarMul uses the EIP-196 ecMul precompile
+    function scalarMul(G1Point memory p, uint256 s) internal view returns (G1Point memory r) {
+        uint256[3] memory input = [p.X, p.Y, s];
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 7, input, 0x60, r, 0x40)
+        }
+        require(success, "pairing-mul-failed");
+    }
+
+    // pairing uses the EIP-197 ecPairing precompile, returning true if e(p1[0], p2[0]) * ... * e(p1[3], p2[3]) == 1
+    function pairing(G1Point[4] memory p1, G2Point[4] memory p2) internal view returns (bool) {
+        uint256[24] memory input;
+        for (uint256 i = 0; i < 4; i++) {
+            input[i * 6 + 0] = p1[i].X;
+            input[i * 6 + 1] = p1[i].Y;
+            input[i * 6 + 2] = p2[i].X[0];
+            input[i * 6 + 3] = p2[i].X[1];
+            input[i * 6 + 4] = p2[i].Y[0];
+            input[i * 6 + 5] = p2[i].Y[1];
+        }
+        uint256[1] memory out;
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 8, input, 0x300, out, 0x20)
+        }
+        require(success, "pairing-opcode-failed");
+        return out[0] != 0;
+    }
+}
+
+contract Verifier {
+    uint256 constant SNARK_SCALAR_FIELD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+
+    struct VerifyingKey {
+        Pairing.G1Point alpha1;
+        Pairing.G2Point beta2;
+        Pairing.G2Point gamma2;
+        Pairing.G2Point delta2;
+        Pairing.G1Point[] IC;
+    }
+
+    function verifyingKey() internal pure returns (VerifyingKey memory vk) {
+        vk.alpha1 = Pairing.G1Point(
+            {{.Alpha.X}},
+            {{.Alpha.Y}}
+        );
+        vk.beta2 = Pairing.G2Point(
+            [{{.Beta.X1}}, {{.Beta.X0}}],
+            [{{.Beta.Y1}}, {{.Beta.Y0}}]
+        );
+        vk.gamma2 = Pairing.G2Point(
+            [{{.Gamma.X1}}, {{.Gamma.X0}}],
+            [{{.Gamma.Y1}}, {{.Gamma.Y0}}]
+        );
+        vk.delta2 = Pairing.G2Point(
+            [{{.Delta.X1}}, {{.Delta.X0}}],
+            [{{.Delta.Y1}}, {{.Delta.Y0}}]
+        );
+        vk.IC = new Pairing.G1Point[]({{len .IC}});
+{{- range $i, $p := .IC}}
+        vk.IC[{{$i}}] = Pairing.G1Point(
+            {{$p.X}},
+            {{$p.Y}}
+        );
+{{- end}}
+    }
+
+    // verifyProof returns true if e(a, b) == e(alpha1, beta2) * e(IC[0] + sum(input[i] * IC[i+1]), gamma2) * e(c, delta2)
+    function verifyProof(
+        uint256[2] memory a,
+        uint256[2][2] memory b,
+        uint256[2] memory c{{if .NPublic}},
+        uint256[{{.NPublic}}] memory input{{end}}
+    ) public view returns (bool) {
+        VerifyingKey memory vk = verifyingKey();
+        Pairing.G1Point memory vkX = vk.IC[0];
+{{- if .NPublic}}
+        for (uint256 i = 0; i < input.length; i++) {
+            require(input[i] < SNARK_SCALAR_FIELD, "verifier-gte-snark-scalar-field");
+            vkX = Pairing.addition(vkX, Pairing.scalarMul(vk.IC[i + 1], input[i]));
+        }
+{{- end}}
+        return Pairing.pairing(
+            [Pairing.negate(Pairing.G1Point(a[0], a[1])), vk.alpha1, vkX, Pairing.G1Point(c[0], c[1])],
+            [Pairing.G2Point(b[0], b[1]), vk.beta2, vk.gamma2, vk.delta2]
+        );
+    }
+}
+`))
+
+// SolidityVerifier returns the source of a Solidity contract with the Vk embedded, which verifies the Groth16 proofs with the EIP-196 and EIP-197 precompiles of the BN128 curve. The arguments of its verifyProof function are given by SolidityCalldata
+func SolidityVerifier(vk groth16.Vk) (string, error) {
+	if len(vk.IC) == 0 {
+		return "", errors.New("vk without IC points")
+	}
+	data := struct {
+		NPublic            int
+		Alpha              solidityG1
+		Beta, Gamma, Delta solidityG2
+		IC                 []solidityG1
+	}{
+		NPublic: len(vk.IC) - 1,
+		Alpha:   g1ToSolidity(vk.G1.Alpha),
+		Beta:    g2ToSolidity(vk.G2.Beta),
+		Gamma:   g2ToSolidity(vk.G2.Gamma),
+		Delta:   g2ToSolidity(vk.G2.Delta),
+	}
+	for _, p := range vk.IC {
+		data.IC = append(data.IC, g1ToSolidity(p))
+	}
+	var buf bytes.Buffer
+	if err := solidityVerifierTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// hexUint256 returns the value as the 0x prefixed hexadecimal string of 32 bytes
+func hexUint256(s string) string {
+	v, _ := new(big.Int).SetString(s, 10)
+	return fmt.Sprintf("\"0x%064x\"", v)
+}
+
+// SolidityCalldata returns the arguments of the verifyProof function of the contract generated by SolidityVerifier for the proof and the public signals, in the format [a],[b],[c],[input]. Without public signals the input is omitted, as in the verifyProof function
+func SolidityCalldata(proof groth16.Proof, publicSignals []*big.Int) string {
+	a := g1ToSolidity(proof.PiA)
+	b := g2ToSolidity(proof.PiB)
+	c := g1ToSolidity(proof.PiC)
+	calldata := fmt.Sprintf("[%s,%s],[[%s,%s],[%s,%s]],[%s,%s]",
+		hexUint256(a.X), hexUint256(a.Y),
+		hexUint256(b.X1), hexUint256(b.X0), hexUint256(b.Y1), hexUint256(b.Y0),
+		hexUint256(c.X), hexUint256(c.Y))
+	if len(publicSignals) == 0 {
+		return calldata
+	}
+	var inputs []string
+	for _, s := range publicSignals {
+		inputs = append(inputs, hexUint256(new(big.Int).Mod(s, groth16.Utils.Bn.R).String()))
+	}
+	return calldata + ",[" + strings.Join(inputs, ",") + "]"
+}
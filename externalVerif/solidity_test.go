@@ -0,0 +1,101 @@
+package externalVerif
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnaucube/go-snark-study/groth16"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// checkGolden compares the output with the golden file of testdata, or updates it with the -update flag
+func checkGolden(t *testing.T, name, output string) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := ioutil.WriteFile(path, []byte(output), 0644)
+		assert.Nil(t, err)
+	}
+	golden, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, string(golden), output)
+}
+
+func TestSolidityVerifierGolden(t *testing.T) {
+	// Vk and Proof with points of known discrete logarithms
+	g1 := func(k int64) [3]*big.Int {
+		return groth16.Utils.Bn.G1.MulScalar(groth16.Utils.Bn.G1.G, big.NewInt(k))
+	}
+	g2 := func(k int64) [3][2]*big.Int {
+		return groth16.Utils.Bn.G2.MulScalar(groth16.Utils.Bn.G2.G, big.NewInt(k))
+	}
+	var vk groth16.Vk
+	vk.G1.Alpha = g1(2)
+	vk.G2.Beta = g2(3)
+	vk.G2.Gamma = g2(5)
+	vk.G2.Delta = g2(7)
+	vk.IC = [][3]*big.Int{g1(11), g1(13)}
+	verifier, err := SolidityVerifier(vk)
+	assert.Nil(t, err)
+	checkGolden(t, "verifier.sol", verifier)
+
+	proof := groth16.Proof{PiA: g1(17), PiB: g2(19), PiC: g1(23)}
+	checkGolden(t, "calldata.txt", SolidityCalldata(proof, []*big.Int{big.NewInt(int64(35))}))
+
+	_, err = SolidityVerifier(groth16.Vk{})
+	assert.NotNil(t, err)
+}
+
+func TestSolidityCalldata(t *testing.T) {
+	circuit, setup, w, publicSignals := testGrothSetup(t)
+	proof, err := groth16.GenerateProofs(*circuit, setup.Pk, w)
+	assert.Nil(t, err)
+
+	// parse the calldata as the contract does, and check the pairings of verifyProof
+	var args [4]json.RawMessage
+	err = json.Unmarshal([]byte("["+SolidityCalldata(proof, publicSignals)+"]"), &args)
+	assert.Nil(t, err)
+	var a, c [2]string
+	var b [2][2]string
+	var input []string
+	assert.Nil(t, json.Unmarshal(args[0], &a))
+	assert.Nil(t, json.Unmarshal(args[1], &b))
+	assert.Nil(t, json.Unmarshal(args[2], &c))
+	assert.Nil(t, json.Unmarshal(args[3], &input))
+	h := func(s string) *big.Int {
+		v, ok := new(big.Int).SetString(s[2:], 16)
+		assert.True(t, ok)
+		return v
+	}
+	one := big.NewInt(int64(1))
+	var parsed groth16.Proof
+	parsed.PiA = [3]*big.Int{h(a[0]), h(a[1]), one}
+	parsed.PiB = [3][2]*big.Int{{h(b[0][1]), h(b[0][0])}, {h(b[1][1]), h(b[1][0])}, {one, big.NewInt(int64(0))}}
+	parsed.PiC = [3]*big.Int{h(c[0]), h(c[1]), one}
+	var signals []*big.Int
+	for _, s := range input {
+		signals = append(signals, h(s))
+	}
+
+	bn := groth16.Utils.Bn
+	vkX := setup.Vk.IC[0]
+	for i, s := range signals {
+		vkX = bn.G1.Add(vkX, bn.G1.MulScalar(setup.Vk.IC[i+1], s))
+	}
+	// e(-a, b) * e(alpha1, beta2) * e(vkX, gamma2) * e(c, delta2) == 1
+	res := bn.Fq12.Mul(bn.Pairing(bn.G1.Neg(parsed.PiA), parsed.PiB), bn.Pairing(setup.Vk.G1.Alpha, setup.Vk.G2.Beta))
+	res = bn.Fq12.Mul(res, bn.Pairing(vkX, setup.Vk.G2.Gamma))
+	res = bn.Fq12.Mul(res, bn.Pairing(parsed.PiC, setup.Vk.G2.Delta))
+	assert.True(t, bn.Fq12.Equal(bn.Fq12.One(), res))
+	assert.True(t, groth16.VerifyProof(setup.Vk, parsed, signals, false))
+
+	// without public signals the verifyProof function has no input argument
+	var noInputArgs []json.RawMessage
+	assert.Nil(t, json.Unmarshal([]byte("["+SolidityCalldata(proof, nil)+"]"), &noInputArgs))
+	assert.Equal(t, 3, len(noInputArgs))
+}
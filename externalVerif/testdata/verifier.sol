@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: GPL-3.0
+// Groth16 verifier generated by go-snark-study
+pragma solidity ^0.8.0;
+
+library Pairing {
+    uint256 constant PRIME_Q = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+
+    struct G1Point {
+        uint256 X;
+        uint256 Y;
+    }
+
+    // the coordinates are encoded as [imaginary, real]
+    struct G2Point {
+        uint256[2] X;
+        uint256[2] Y;
+    }
+
+    // the point at infinity is (0, 0), and it is its own negation
+    function negate(G1Point memory p) internal pure returns (G1Point memory) {
+        if (p.Y % PRIME_Q == 0) {
+            return G1Point(0, 0);
+        }
+        return G1Point(p.X, PRIME_Q - (p.Y % PRIME_Q));
+    }
+
+    // addition uses the EIP-196 ecAdd precompile
+    function addition(G1Point memory p1, G1Point memory p2) internal view returns (G1Point memory r) {
+        uint256[4] memory input = [p1.X, p1.Y, p2.X, p2.Y];
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 6, input, 0x80, r, 0x40)
+        }
+        require(success, "pairing-add-failed");
+    }
+
+    // scalarMul uses the EIP-196 ecMul precompile
+    function scalarMul(G1Point memory p, uint256 s) internal view returns (G1Point memory r) {
+        uint256[3] memory input = [p.X, p.Y, s];
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 7, input, 0x60, r, 0x40)
+        }
+        require(success, "pairing-mul-failed");
+    }
+
+    // pairing uses the EIP-197 ecPairing precompile, returning true if e(p1[0], p2[0]) * ... * e(p1[3], p2[3]) == 1
+    function pairing(G1Point[4] memory p1, G2Point[4] memory p2) internal view returns (bool) {
+        uint256[24] memory input;
+        for (uint256 i = 0; i < 4; i++) {
+            input[i * 6 + 0] = p1[i].X;
+            input[i * 6 + 1] = p1[i].Y;
+            input[i * 6 + 2] = p2[i].X[0];
+            input[i * 6 + 3] = p2[i].X[1];
+            input[i * 6 + 4] = p2[i].Y[0];
+            input[i * 6 + 5] = p2[i].Y[1];
+        }
+        uint256[1] memory out;
+        bool success;
+        assembly {
+            success := staticcall(sub(gas(), 2000), 8, input, 0x300, out, 0x20)
+        }
+        require(success, "pairing-opcode-failed");
+        return out[0] != 0;
+    }
+}
+
+contract Verifier {
+    uint256 constant SNARK_SCALAR_FIELD = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+
+    struct VerifyingKey {
+        Pairing.G1Point alpha1;
+        Pairing.G2Point beta2;
+        Pairing.G2Point gamma2;
+        Pairing.G2Point delta2;
+        Pairing.G1Point[] IC;
+    }
+
+    function verifyingKey() internal pure returns (VerifyingKey memory vk) {
+        vk.alpha1 = Pairing.G1Point(
+            1368015179489954701390400359078579693043519447331113978918064868415326638035,
+            9918110051302171585080402603319702774565515993150576347155970296011118125764
+        );
+        vk.beta2 = Pairing.G2Point(
+            [7273165102799931111715871471550377909735733521218303035754523677688038059653, 2725019753478801796453339367788033689375851816420509565303521482350756874229],
+            [957874124722006818841961785324909313781880061366718538693995380805373202866, 2512659008974376214222774206987427162027254181373325676825515531566330959255]
+        );
+        vk.gamma2 = Pairing.G2Point(
+            [4540444681147253467785307942530223364530218361853237193970751657229138047649, 20954117799226682825035885491234530437475518021362091509513177301640194298072],
+            [11631839690097995216017572651900167465857396346217730511548857041925508482915, 21508930868448350162258892668132814424284302804699005394342512102884055673846]
+        );
+        vk.delta2 = Pairing.G2Point(
+            [18551411094430470096460536606940536822990217226529861227533666875800903099477, 15512671280233143720612069991584289591749188907863576513414377951116606878472],
+            [1711576522631428957817575436337311654689480489843856945284031697403898093784, 13376798835316611669264291046140500151806347092962367781523498857425536295743]
+        );
+        vk.IC = new Pairing.G1Point[](2);
+        vk.IC[0] = Pairing.G1Point(
+            19033251874843656108471242320417533909414939332036131356573128480367742634479,
+            20792135454608030201903199625673964159744755218442260092768620403349374102584
+        );
+        vk.IC[1] = Pairing.G1Point(
+            2672242651313367459976336264061690128665099451055893690004467838496751824703,
+            18247534626997477790812670345925575171672701304065784723769023620148097699216
+        );
+    }
+
+    // verifyProof returns true if e(a, b) == e(alpha1, beta2) * e(IC[0] + sum(input[i] * IC[i+1]), gamma2) * e(c, delta2)
+    function verifyProof(
+        uint256[2] memory a,
+        uint256[2][2] memory b,
+        uint256[2] memory c,
+        uint256[1] memory input
+    ) public view returns (bool) {
+        VerifyingKey memory vk = verifyingKey();
+        Pairing.G1Point memory vkX = vk.IC[0];
+        for (uint256 i = 0; i < input.length; i++) {
+            require(input[i] < SNARK_SCALAR_FIELD, "verifier-gte-snark-scalar-field");
+            vkX = Pairing.addition(vkX, Pairing.scalarMul(vk.IC[i + 1], input[i]));
+        }
+        return Pairing.pairing(
+            [Pairing.negate(Pairing.G1Point(a[0], a[1])), vk.alpha1, vkX, Pairing.G1Point(c[0], c[1])],
+            [Pairing.G2Point(b[0], b[1]), vk.beta2, vk.gamma2, vk.delta2]
+        );
+    }
+}
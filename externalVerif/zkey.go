@@ -0,0 +1,537 @@
+package externalVerif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/arnaucube/go-snark-study/circuitcompiler"
+	"github.com/arnaucube/go-snark-study/groth16"
+	"github.com/arnaucube/go-snark-study/r1csqap"
+)
+
+// sections of the snarkjs .zkey file
+const (
+	zkeySectionHeader      = 1
+	zkeySectionGrothHeader = 2
+	zkeySectionIC          = 3
+	zkeySectionCoefs       = 4
+	zkeySectionA           = 5
+	zkeySectionB1          = 6
+	zkeySectionB2          = 7
+	zkeySectionC           = 8
+	zkeySectionH           = 9
+	zkeyVersion            = 1
+	zkeyProtocolGroth16    = 1
+	zkeyFieldSize          = 32
+)
+
+var zkeyMagic = []byte("zkey")
+
+// montR returns 2^256 mod m, the Montgomery factor used by snarkjs for the fields of 32 bytes
+func montR(m *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(int64(1)), 8*zkeyFieldSize), m)
+}
+
+// putLE writes a in little-endian on the zkeyFieldSize bytes of b
+func putLE(b []byte, a *big.Int) {
+	var be [zkeyFieldSize]byte
+	a.FillBytes(be[:])
+	for i := 0; i < zkeyFieldSize; i++ {
+		b[i] = be[zkeyFieldSize-1-i]
+	}
+}
+
+// getLE reads a little-endian value from the zkeyFieldSize bytes of b
+func getLE(b []byte) *big.Int {
+	var be [zkeyFieldSize]byte
+	for i := 0; i < zkeyFieldSize; i++ {
+		be[i] = b[zkeyFieldSize-1-i]
+	}
+	return new(big.Int).SetBytes(be[:])
+}
+
+// zkeyWriter writes the values of a zkey section, with the field elements of the points in Montgomery form
+type zkeyWriter struct {
+	buf   bytes.Buffer
+	montQ *big.Int
+}
+
+func (zw *zkeyWriter) uint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	zw.buf.Write(b[:])
+}
+
+func (zw *zkeyWriter) le(a *big.Int) {
+	var b [zkeyFieldSize]byte
+	putLE(b[:], a)
+	zw.buf.Write(b[:])
+}
+
+func (zw *zkeyWriter) fq(a *big.Int) {
+	zw.le(groth16.Utils.Bn.Fq1.Mul(a, zw.montQ))
+}
+
+func (zw *zkeyWriter) g1(p [3]*big.Int) {
+	if groth16.Utils.Bn.G1.IsZero(p) {
+		zw.buf.Write(make([]byte, 2*zkeyFieldSize))
+		return
+	}
+	a := groth16.Utils.Bn.G1.Affine(p)
+	zw.fq(a[0])
+	zw.fq(a[1])
+}
+
+func (zw *zkeyWriter) g2(p [3][2]*big.Int) {
+	if groth16.Utils.Bn.G2.IsZero(p) {
+		zw.buf.Write(make([]byte, 4*zkeyFieldSize))
+		return
+	}
+	a := groth16.Utils.Bn.G2.Affine(p)
+	zw.fq(a[0][0])
+	zw.fq(a[0][1])
+	zw.fq(a[1][0])
+	zw.fq(a[1][1])
+}
+
+// zkeyReader reads the values of a zkey section, keeping the first error
+type zkeyReader struct {
+	b        []byte
+	montQInv *big.Int
+	err      error
+}
+
+func (zr *zkeyReader) fail(msg string) {
+	if zr.err == nil {
+		zr.err = errors.New(msg)
+	}
+}
+
+func (zr *zkeyReader) read(n int) []byte {
+	if zr.err != nil {
+		return nil
+	}
+	if len(zr.b) < n {
+		zr.fail("zkey section too short")
+		return nil
+	}
+	b := zr.b[:n]
+	zr.b = zr.b[n:]
+	return b
+}
+
+func (zr *zkeyReader) uint32() uint32 {
+	b := zr.read(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (zr *zkeyReader) le() *big.Int {
+	b := zr.read(zkeyFieldSize)
+	if b == nil {
+		return big.NewInt(int64(0))
+	}
+	return getLE(b)
+}
+
+func (zr *zkeyReader) fq() *big.Int {
+	a := zr.le()
+	if a.Cmp(groth16.Utils.Bn.Q) >= 0 {
+		zr.fail("zkey field element out of the field")
+	}
+	return groth16.Utils.Bn.Fq1.Mul(a, zr.montQInv)
+}
+
+func (zr *zkeyReader) g1() [3]*big.Int {
+	x := zr.fq()
+	y := zr.fq()
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return [3]*big.Int{big.NewInt(int64(0)), big.NewInt(int64(0)), big.NewInt(int64(0))}
+	}
+	p := [3]*big.Int{x, y, big.NewInt(int64(1))}
+	if zr.err == nil && !groth16.Utils.Bn.G1.IsOnCurve(p) {
+		zr.fail("zkey G1 point not on the curve")
+	}
+	return p
+}
+
+func (zr *zkeyReader) g2() [3][2]*big.Int {
+	x := [2]*big.Int{zr.fq(), zr.fq()}
+	y := [2]*big.Int{zr.fq(), zr.fq()}
+	if x[0].Sign() == 0 && x[1].Sign() == 0 && y[0].Sign() == 0 && y[1].Sign() == 0 {
+		return groth16.Utils.Bn.G2.Zero()
+	}
+	p := [3][2]*big.Int{x, y, groth16.Utils.Bn.Fq2.One()}
+	if zr.err == nil && !groth16.Utils.Bn.G2.IsOnCurve(p) {
+		zr.fail("zkey G2 point not on the curve")
+	}
+	return p
+}
+
+func (zr *zkeyReader) g1s(n int) [][3]*big.Int {
+	var ps [][3]*big.Int
+	for i := 0; i < n && zr.err == nil; i++ {
+		ps = append(ps, zr.g1())
+	}
+	return ps
+}
+
+// g1FFT performs over the G1 points the same transform than the ntt of the r1csqap package, v_j = Σ v_i * omega^(i*j), where omega is a primitive len(v)-th root of unity
+func g1FFT(v [][3]*big.Int, omega *big.Int) [][3]*big.Int {
+	n := len(v)
+	r := make([][3]*big.Int, n)
+	copy(r, v)
+	// bit reversal order
+	j := 0
+	for i := 1; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			r[i], r[j] = r[j], r[i]
+		}
+	}
+	for m := 2; m <= n; m <<= 1 {
+		wm := groth16.Utils.FqR.Exp(omega, big.NewInt(int64(n/m)))
+		w := big.NewInt(int64(1))
+		for k := 0; k < m/2; k++ {
+			for i := k; i < n; i += m {
+				t := r[i+m/2]
+				if k > 0 {
+					t = groth16.Utils.Bn.G1.MulScalar(t, w)
+				}
+				u := r[i]
+				r[i] = groth16.Utils.Bn.G1.Add(u, t)
+				r[i+m/2] = groth16.Utils.Bn.G1.Sub(u, t)
+			}
+			w = groth16.Utils.FqR.Mul(w, wm)
+		}
+	}
+	return r
+}
+
+// hToPowersTauDelta converts the H points of the zkey into the Pk.PowersTauDelta. snarkjs stores H_i = L_(2i+1)(τ) / δ, the Lagrange basis of the domain of size 2n at the odd points x_i = g * ω^i (being g the 2n-th root of unity), where Z(x_i) = -2. Then τ^j * Z(τ) / δ = -2 * Σ x_i^j * H_i = -2 * g^j * FFT(H)_j
+func hToPowersTauDelta(h [][3]*big.Int) ([][3]*big.Int, error) {
+	d, d2, err := zkeyDomains(len(h))
+	if err != nil {
+		return nil, err
+	}
+	ptd := g1FFT(h, d.Omega)
+	k := groth16.Utils.FqR.Neg(big.NewInt(int64(2)))
+	for j := range ptd {
+		ptd[j] = groth16.Utils.Bn.G1.MulScalar(ptd[j], k)
+		k = groth16.Utils.FqR.Mul(k, d2.Omega)
+	}
+	return ptd, nil
+}
+
+// powersTauDeltaToH is the inverse of hToPowersTauDelta, H = IFFT(PowersTauDelta_j / (-2 * g^j))
+func powersTauDeltaToH(ptd [][3]*big.Int, n int) ([][3]*big.Int, error) {
+	d, d2, err := zkeyDomains(n)
+	if err != nil {
+		return nil, err
+	}
+	if len(ptd) < n {
+		return nil, errors.New("not enough powers of τ for the domain")
+	}
+	h := make([][3]*big.Int, n)
+	gInv := groth16.Utils.FqR.Inverse(d2.Omega)
+	// the 1/N of the inverse transform is applied together with 1/(-2 * g^j)
+	k := groth16.Utils.FqR.Mul(d.NInv, groth16.Utils.FqR.Inverse(groth16.Utils.FqR.Neg(big.NewInt(int64(2)))))
+	for j := 0; j < n; j++ {
+		h[j] = groth16.Utils.Bn.G1.MulScalar(ptd[j], k)
+		k = groth16.Utils.FqR.Mul(k, gInv)
+	}
+	return g1FFT(h, d.OmegaInv), nil
+}
+
+// zkeyDomains returns the domain of size n and the one of size 2n
+func zkeyDomains(n int) (r1csqap.Domain, r1csqap.Domain, error) {
+	d, err := groth16.Utils.PF.NewDomain(n)
+	if err != nil {
+		return r1csqap.Domain{}, r1csqap.Domain{}, err
+	}
+	if d.N != n {
+		return r1csqap.Domain{}, r1csqap.Domain{}, errors.New("zkey domain size is not a power of two")
+	}
+	d2, err := groth16.Utils.PF.NewDomain(2 * n)
+	if err != nil {
+		return r1csqap.Domain{}, r1csqap.Domain{}, err
+	}
+	return d, d2, nil
+}
+
+// ExportZkey writes the Groth16 Setup in the snarkjs .zkey format, to be used by the snarkjs prover. The circuit must have the R1CS, from which only the matrices A and B are stored, as snarkjs computes C from them. The contributions section is not written
+func ExportZkey(w io.Writer, setup groth16.Setup, circuit circuitcompiler.Circuit) error {
+	if len(circuit.R1CS.A) == 0 {
+		return errors.New("the circuit has no R1CS")
+	}
+	d, err := groth16.Utils.PF.NewDomain(len(circuit.R1CS.A))
+	if err != nil {
+		return err
+	}
+	h, err := powersTauDeltaToH(setup.Pk.PowersTauDelta, d.N)
+	if err != nil {
+		return err
+	}
+	montQ := montR(groth16.Utils.Bn.Q)
+	montR2 := groth16.Utils.FqR.Square(montR(groth16.Utils.Bn.R))
+	newSection := func() *zkeyWriter {
+		return &zkeyWriter{montQ: montQ}
+	}
+	var sections []*zkeyWriter
+
+	s := newSection()
+	s.uint32(zkeyProtocolGroth16)
+	sections = append(sections, s)
+
+	s = newSection()
+	s.uint32(zkeyFieldSize)
+	s.le(groth16.Utils.Bn.Q)
+	s.uint32(zkeyFieldSize)
+	s.le(groth16.Utils.Bn.R)
+	s.uint32(uint32(circuit.NVars))
+	s.uint32(uint32(circuit.NPublic))
+	s.uint32(uint32(d.N))
+	s.g1(setup.Vk.G1.Alpha)
+	s.g1(setup.Pk.G1.Beta)
+	s.g2(setup.Vk.G2.Beta)
+	s.g2(setup.Vk.G2.Gamma)
+	s.g1(setup.Pk.G1.Delta)
+	s.g2(setup.Vk.G2.Delta)
+	sections = append(sections, s)
+
+	s = newSection()
+	for _, p := range setup.Vk.IC {
+		s.g1(p)
+	}
+	sections = append(sections, s)
+
+	// coefficients of A and B, with the values multiplied by R^2 as the snarkjs prover expects
+	s = newSection()
+	var coefs zkeyWriter
+	nCoefs := 0
+	for m, matrix := range [][][]*big.Int{circuit.R1CS.A, circuit.R1CS.B} {
+		for i, row := range matrix {
+			for j := 0; j < len(row) && j < circuit.NVars; j++ {
+				if row[j].Sign() == 0 {
+					continue
+				}
+				coefs.uint32(uint32(m))
+				coefs.uint32(uint32(i))
+				coefs.uint32(uint32(j))
+				coefs.le(groth16.Utils.FqR.Mul(row[j], montR2))
+				nCoefs++
+			}
+		}
+	}
+	s.uint32(uint32(nCoefs))
+	s.buf.Write(coefs.buf.Bytes())
+	sections = append(sections, s)
+
+	s = newSection()
+	for _, p := range setup.Pk.G1.At[:circuit.NVars] {
+		s.g1(p)
+	}
+	sections = append(sections, s)
+	s = newSection()
+	for _, p := range setup.Pk.G1.BACGamma[:circuit.NVars] {
+		s.g1(p)
+	}
+	sections = append(sections, s)
+	s = newSection()
+	for _, p := range setup.Pk.G2.BACGamma[:circuit.NVars] {
+		s.g2(p)
+	}
+	sections = append(sections, s)
+	s = newSection()
+	for _, p := range setup.Pk.BACDelta[circuit.NPublic+1 : circuit.NVars] {
+		s.g1(p)
+	}
+	sections = append(sections, s)
+	s = newSection()
+	for _, p := range h {
+		s.g1(p)
+	}
+	sections = append(sections, s)
+
+	var file zkeyWriter
+	file.buf.Write(zkeyMagic)
+	file.uint32(zkeyVersion)
+	file.uint32(uint32(len(sections)))
+	for i, s := range sections {
+		file.uint32(uint32(i + 1))
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(s.buf.Len()))
+		file.buf.Write(size[:])
+		file.buf.Write(s.buf.Bytes())
+	}
+	_, err = w.Write(file.buf.Bytes())
+	return err
+}
+
+// readZkeySections returns the content of each section of the zkey file
+func readZkeySections(b []byte) (map[uint32][]byte, error) {
+	if len(b) < 12 || !bytes.Equal(b[:4], zkeyMagic) {
+		return nil, errors.New("not a zkey file")
+	}
+	if binary.LittleEndian.Uint32(b[4:8]) != zkeyVersion {
+		return nil, errors.New("zkey version not supported")
+	}
+	nSections := binary.LittleEndian.Uint32(b[8:12])
+	b = b[12:]
+	sections := make(map[uint32][]byte)
+	for i := uint32(0); i < nSections; i++ {
+		if len(b) < 12 {
+			return nil, errors.New("zkey file too short")
+		}
+		sType := binary.LittleEndian.Uint32(b[:4])
+		size := binary.LittleEndian.Uint64(b[4:12])
+		b = b[12:]
+		if size > uint64(len(b)) {
+			return nil, errors.New("zkey file too short")
+		}
+		if _, ok := sections[sType]; ok {
+			return nil, errors.New("duplicated zkey section")
+		}
+		sections[sType] = b[:size]
+		b = b[size:]
+	}
+	for s := uint32(zkeySectionHeader); s <= zkeySectionH; s++ {
+		if _, ok := sections[s]; !ok {
+			return nil, errors.New("missing zkey section")
+		}
+	}
+	return sections, nil
+}
+
+// ImportZkey reads a Groth16 proving key in the snarkjs .zkey format, returning the Setup (without the Toxic values) and the Circuit with the number of variables and the R1CS matrices A and B. The matrix C is nil, as in the zkey, so the prover obtains it from A and B.
+// The zkey stores only the nonzero coefficients, but the matrices of the Circuit are dense: A and B take 2 * domainSize * NVars pointers (8 bytes each, the zeros are shared), so a circuit of 2^20 constraints and 2^20 variables would need 16 TiB
+func ImportZkey(r io.Reader) (groth16.Setup, circuitcompiler.Circuit, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, err
+	}
+	sections, err := readZkeySections(b)
+	if err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, err
+	}
+	montQInv := groth16.Utils.Bn.Fq1.Inverse(montR(groth16.Utils.Bn.Q))
+	section := func(s uint32) *zkeyReader {
+		return &zkeyReader{b: sections[s], montQInv: montQInv}
+	}
+
+	zr := section(zkeySectionHeader)
+	if zr.uint32() != zkeyProtocolGroth16 {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, errors.New("zkey protocol is not groth16")
+	}
+
+	var setup groth16.Setup
+	var circuit circuitcompiler.Circuit
+	zr = section(zkeySectionGrothHeader)
+	if zr.uint32() != zkeyFieldSize || zr.le().Cmp(groth16.Utils.Bn.Q) != 0 ||
+		zr.uint32() != zkeyFieldSize || zr.le().Cmp(groth16.Utils.Bn.R) != 0 {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, errors.New("zkey curve is not bn128")
+	}
+	circuit.NVars = int(zr.uint32())
+	circuit.NPublic = int(zr.uint32())
+	domainSize := int(zr.uint32())
+	setup.Vk.G1.Alpha = zr.g1()
+	setup.Pk.G1.Alpha = setup.Vk.G1.Alpha
+	setup.Pk.G1.Beta = zr.g1()
+	setup.Vk.G2.Beta = zr.g2()
+	setup.Pk.G2.Beta = setup.Vk.G2.Beta
+	setup.Vk.G2.Gamma = zr.g2()
+	setup.Pk.G1.Delta = zr.g1()
+	setup.Vk.G2.Delta = zr.g2()
+	setup.Pk.G2.Delta = setup.Vk.G2.Delta
+	if zr.err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, zr.err
+	}
+	// the sizes are checked against the sections before allocating the R1CS
+	if circuit.NPublic >= circuit.NVars || domainSize < 1 ||
+		circuit.NVars > len(sections[zkeySectionA])/(2*zkeyFieldSize) || domainSize > len(sections[zkeySectionH])/(2*zkeyFieldSize) {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, errors.New("zkey header not consistent with the sections")
+	}
+
+	zr = section(zkeySectionIC)
+	setup.Vk.IC = zr.g1s(circuit.NPublic + 1)
+	if zr.err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, zr.err
+	}
+	if err := groth16.ValidateVk(setup.Vk); err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, err
+	}
+
+	// dense R1CS matrices from the coefficients, with one row for each point of the domain
+	invR2 := groth16.Utils.FqR.Inverse(groth16.Utils.FqR.Square(montR(groth16.Utils.Bn.R)))
+	var matrices [2][][]*big.Int
+	for m := range matrices {
+		matrices[m] = make([][]*big.Int, domainSize)
+		for i := range matrices[m] {
+			matrices[m][i] = r1csqap.ArrayOfBigZeros(circuit.NVars)
+		}
+	}
+	zr = section(zkeySectionCoefs)
+	nCoefs := int(zr.uint32())
+	for i := 0; i < nCoefs && zr.err == nil; i++ {
+		m := zr.uint32()
+		c := int(zr.uint32())
+		s := int(zr.uint32())
+		v := zr.le()
+		if zr.err != nil {
+			break
+		}
+		if m > 1 || c >= domainSize || s >= circuit.NVars {
+			zr.fail("zkey coefficient out of the R1CS")
+			break
+		}
+		row := matrices[m][c]
+		row[s] = groth16.Utils.FqR.Add(row[s], groth16.Utils.FqR.Mul(v, invR2))
+	}
+	if zr.err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, zr.err
+	}
+	circuit.R1CS.A = matrices[0]
+	circuit.R1CS.B = matrices[1]
+
+	zr = section(zkeySectionA)
+	setup.Pk.G1.At = zr.g1s(circuit.NVars)
+	zr2 := section(zkeySectionB1)
+	setup.Pk.G1.BACGamma = zr2.g1s(circuit.NVars)
+	zr3 := section(zkeySectionB2)
+	for i := 0; i < circuit.NVars && zr3.err == nil; i++ {
+		setup.Pk.G2.BACGamma = append(setup.Pk.G2.BACGamma, zr3.g2())
+	}
+	zr4 := section(zkeySectionC)
+	zero3 := [3]*big.Int{big.NewInt(int64(0)), big.NewInt(int64(0)), big.NewInt(int64(0))}
+	for i := 0; i < circuit.NPublic+1; i++ {
+		setup.Pk.BACDelta = append(setup.Pk.BACDelta, zero3)
+	}
+	setup.Pk.BACDelta = append(setup.Pk.BACDelta, zr4.g1s(circuit.NVars-circuit.NPublic-1)...)
+	zr5 := section(zkeySectionH)
+	h := zr5.g1s(domainSize)
+	for _, s := range []*zkeyReader{zr, zr2, zr3, zr4, zr5} {
+		if s.err != nil {
+			return groth16.Setup{}, circuitcompiler.Circuit{}, s.err
+		}
+	}
+
+	setup.Pk.PowersTauDelta, err = hToPowersTauDelta(h)
+	if err != nil {
+		return groth16.Setup{}, circuitcompiler.Circuit{}, err
+	}
+	// Z(x) = x^n - 1
+	setup.Pk.Z = r1csqap.ArrayOfBigZeros(domainSize + 1)
+	setup.Pk.Z[0] = groth16.Utils.FqR.Neg(big.NewInt(int64(1)))
+	setup.Pk.Z[domainSize] = big.NewInt(int64(1))
+	return setup, circuit, nil
+}
@@ -0,0 +1,95 @@
+package externalVerif
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/arnaucube/go-snark-study/circuitcompiler"
+	"github.com/arnaucube/go-snark-study/groth16"
+	"github.com/stretchr/testify/assert"
+)
+
+// testGrothSetup returns the Groth16 setup of the circuit y = x^3 + x + 5 and its witness
+func testGrothSetup(t *testing.T) (*circuitcompiler.Circuit, groth16.Setup, []*big.Int, []*big.Int) {
+	code := `
+	func main(private s0, public s1):
+		s2 = s0 * s0
+		s3 = s2 * s0
+		s4 = s3 + s0
+		s5 = s4 + 5
+		equals(s1, s5)
+		out = 1 * 1
+	`
+	parser := circuitcompiler.NewParser(strings.NewReader(code))
+	circuit, err := parser.Parse()
+	assert.Nil(t, err)
+	publicSignals := []*big.Int{big.NewInt(int64(35))}
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, publicSignals)
+	assert.Nil(t, err)
+	a, b, c := circuit.GenerateR1CS()
+	alphas, betas, gammas, _, err := groth16.Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
+	setup, err := groth16.GenerateTrustedSetup(len(w), *circuit, alphas, betas, gammas)
+	assert.Nil(t, err)
+	return circuit, setup, w, publicSignals
+}
+
+func TestZkeyH(t *testing.T) {
+	_, setup, _, _ := testGrothSetup(t)
+	n := len(setup.Pk.Z) - 1
+
+	// the H points are L_(2i+1)(τ) / δ, over the domain of size 2n
+	h, err := powersTauDeltaToH(setup.Pk.PowersTauDelta, n)
+	assert.Nil(t, err)
+	d2, err := groth16.Utils.PF.NewDomain(2 * n)
+	assert.Nil(t, err)
+	l := groth16.Utils.PF.LagrangeBasisAt(d2, setup.Toxic.T)
+	for i := 0; i < n; i++ {
+		expected := groth16.Utils.Bn.G1.MulScalar(groth16.Utils.Bn.G1.G, groth16.Utils.FqR.Div(l[2*i+1], setup.Toxic.Kdelta))
+		assert.True(t, groth16.Utils.Bn.G1.Equal(expected, h[i]))
+	}
+
+	ptd, err := hToPowersTauDelta(h)
+	assert.Nil(t, err)
+	for j := 0; j < n; j++ {
+		assert.True(t, groth16.Utils.Bn.G1.Equal(setup.Pk.PowersTauDelta[j], ptd[j]))
+	}
+}
+
+func TestZkeyExportImport(t *testing.T) {
+	circuit, setup, w, publicSignals := testGrothSetup(t)
+
+	var buf bytes.Buffer
+	err := ExportZkey(&buf, setup, *circuit)
+	assert.Nil(t, err)
+	zkey := buf.Bytes()
+	assert.Equal(t, "zkey", string(zkey[:4]))
+
+	imported, importedCircuit, err := ImportZkey(bytes.NewReader(zkey))
+	assert.Nil(t, err)
+	assert.Equal(t, circuit.NVars, importedCircuit.NVars)
+	assert.Equal(t, circuit.NPublic, importedCircuit.NPublic)
+	assert.Nil(t, importedCircuit.R1CS.C)
+	assert.Equal(t, len(setup.Vk.IC), len(imported.Vk.IC))
+	for i := range setup.Vk.IC {
+		assert.True(t, groth16.Utils.Bn.G1.Equal(setup.Vk.IC[i], imported.Vk.IC[i]))
+	}
+	assert.True(t, groth16.Utils.Bn.G2.Equal(setup.Vk.G2.Gamma, imported.Vk.G2.Gamma))
+	for i := 0; i < circuit.NVars; i++ {
+		assert.True(t, groth16.Utils.Bn.G1.Equal(setup.Pk.G1.At[i], imported.Pk.G1.At[i]))
+		assert.True(t, groth16.Utils.Bn.G2.Equal(setup.Pk.G2.BACGamma[i], imported.Pk.G2.BACGamma[i]))
+	}
+
+	// proofs generated with the imported key are valid for the original Vk
+	proof, err := groth16.GenerateProofs(importedCircuit, imported.Pk, w)
+	assert.Nil(t, err)
+	assert.True(t, groth16.VerifyProof(setup.Vk, proof, publicSignals, false))
+
+	// corrupted files
+	_, _, err = ImportZkey(bytes.NewReader(zkey[:len(zkey)-1]))
+	assert.NotNil(t, err)
+	_, _, err = ImportZkey(bytes.NewReader(append([]byte("zkez"), zkey[4:]...)))
+	assert.NotNil(t, err)
+}
@@ -8,16 +8,31 @@ import (
 
 // Fq is the Z field over modulus Q
 type Fq struct {
-	Q *big.Int // Q
+	Q    *big.Int // Q
+	mont *FqMont  // Montgomery backend, nil when Q does not fit in it
 }
 
-// NewFq generates a new Fq
+// NewFq generates a new Fq. When Q is odd and smaller than 2^255 (as the BN128 fields), the operations are computed with the FqMont backend
 func NewFq(q *big.Int) Fq {
+	fq := NewFqBigInt(q)
+	if mont, err := NewFqMont(q); err == nil {
+		fq.mont = &mont
+	}
+	return fq
+}
+
+// NewFqBigInt generates a new Fq that computes all the operations with math/big
+func NewFqBigInt(q *big.Int) Fq {
 	return Fq{
-		q,
+		Q: q,
 	}
 }
 
+// useMont returns true if the operation over the given values can be computed with the Montgomery backend
+func (fq Fq) useMont(a, b *big.Int) bool {
+	return fq.mont != nil && fq.mont.inRange(a) && fq.mont.inRange(b)
+}
+
 // Zero returns a Zero value on the Fq
 func (fq Fq) Zero() *big.Int {
 	return big.NewInt(int64(0))
@@ -30,30 +45,47 @@ func (fq Fq) One() *big.Int {
 
 // Add performs an addition on the Fq
 func (fq Fq) Add(a, b *big.Int) *big.Int {
+	if fq.useMont(a, b) {
+		return limbsToBig(fq.mont.Add(limbsFromBig(a), limbsFromBig(b)))
+	}
 	r := new(big.Int).Add(a, b)
 	return new(big.Int).Mod(r, fq.Q)
 }
 
 // Double performs a doubling on the Fq
 func (fq Fq) Double(a *big.Int) *big.Int {
+	if fq.useMont(a, a) {
+		return limbsToBig(fq.mont.Double(limbsFromBig(a)))
+	}
 	r := new(big.Int).Add(a, a)
 	return new(big.Int).Mod(r, fq.Q)
 }
 
 // Sub performs a subtraction on the Fq
 func (fq Fq) Sub(a, b *big.Int) *big.Int {
+	if fq.useMont(a, b) {
+		return limbsToBig(fq.mont.Sub(limbsFromBig(a), limbsFromBig(b)))
+	}
 	r := new(big.Int).Sub(a, b)
 	return new(big.Int).Mod(r, fq.Q)
 }
 
 // Neg performs a negation on the Fq
 func (fq Fq) Neg(a *big.Int) *big.Int {
+	if fq.useMont(a, a) {
+		return limbsToBig(fq.mont.Neg(limbsFromBig(a)))
+	}
 	m := new(big.Int).Neg(a)
 	return new(big.Int).Mod(m, fq.Q)
 }
 
 // Mul performs a multiplication on the Fq
 func (fq Fq) Mul(a, b *big.Int) *big.Int {
+	if fq.useMont(a, b) {
+		// (a * b * R^-1) * R^2 * R^-1 = a * b
+		ab := fq.mont.Mul(limbsFromBig(a), limbsFromBig(b))
+		return limbsToBig(fq.mont.Mul(ab, fq.mont.r2))
+	}
 	m := new(big.Int).Mul(a, b)
 	return new(big.Int).Mod(m, fq.Q)
 }
@@ -93,12 +125,19 @@ func (fq Fq) Div(a, b *big.Int) *big.Int {
 
 // Square performs a square operation on the Fq
 func (fq Fq) Square(a *big.Int) *big.Int {
+	if fq.useMont(a, a) {
+		aa := fq.mont.Square(limbsFromBig(a))
+		return limbsToBig(fq.mont.Mul(aa, fq.mont.r2))
+	}
 	m := new(big.Int).Mul(a, a)
 	return new(big.Int).Mod(m, fq.Q)
 }
 
 // Exp performs the exponential over Fq
 func (fq Fq) Exp(base *big.Int, e *big.Int) *big.Int {
+	if fq.useMont(base, base) && e.Sign() >= 0 {
+		return fq.mont.ToBig(fq.mont.Exp(fq.mont.FromBig(base), e))
+	}
 	res := fq.One()
 	rem := fq.Copy(e)
 	exp := base
@@ -113,6 +152,15 @@ func (fq Fq) Exp(base *big.Int, e *big.Int) *big.Int {
 	return res
 }
 
+// Sqrt returns a square root of a over Fq, and false if a is not a quadratic residue
+func (fq Fq) Sqrt(a *big.Int) (*big.Int, bool) {
+	r := new(big.Int).ModSqrt(fq.Affine(a), fq.Q)
+	if r == nil {
+		return nil, false
+	}
+	return r, true
+}
+
 func (fq Fq) Rand() (*big.Int, error) {
 
 	// twoexp := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(maxbits)), nil)
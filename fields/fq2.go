@@ -132,6 +132,41 @@ func (fq2 Fq2) Square(a [2]*big.Int) [2]*big.Int {
 	}
 }
 
+// Exp performs the exponential over Fq2
+func (fq2 Fq2) Exp(base [2]*big.Int, e *big.Int) [2]*big.Int {
+	res := fq2.One()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = fq2.Square(res)
+		if e.Bit(i) == 1 {
+			res = fq2.Mul(res, base)
+		}
+	}
+	return res
+}
+
+// Sqrt returns a square root of a over Fq2, and false if a is not a quadratic residue. It expects Q = 3 mod 4 and NonResidue = -1, as in the BN128 Fq2
+func (fq2 Fq2) Sqrt(a [2]*big.Int) ([2]*big.Int, bool) {
+	// Algorithm 9 from https://eprint.iacr.org/2012/685.pdf
+	q := fq2.F.Q
+	a1 := fq2.Exp(a, new(big.Int).Rsh(new(big.Int).Sub(q, big.NewInt(int64(3))), 2)) // a^((q-3)/4)
+	alpha := fq2.Mul(fq2.Square(a1), a)
+	x0 := fq2.Mul(a1, a)
+
+	var x [2]*big.Int
+	minusOne := [2]*big.Int{fq2.F.Neg(fq2.F.One()), fq2.F.Zero()}
+	if fq2.Equal(alpha, minusOne) {
+		// x = i * x0
+		x = fq2.Mul([2]*big.Int{fq2.F.Zero(), fq2.F.One()}, x0)
+	} else {
+		b := fq2.Exp(fq2.Add(fq2.One(), alpha), new(big.Int).Rsh(new(big.Int).Sub(q, big.NewInt(int64(1))), 1)) // (1+alpha)^((q-1)/2)
+		x = fq2.Mul(b, x0)
+	}
+	if !fq2.Equal(fq2.Square(x), a) {
+		return [2]*big.Int{}, false
+	}
+	return fq2.Affine(x), true
+}
+
 func (fq2 Fq2) IsZero(a [2]*big.Int) bool {
 	return fq2.F.IsZero(a[0]) && fq2.F.IsZero(a[1])
 }
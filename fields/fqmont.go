@@ -0,0 +1,231 @@
+package fields
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+// MontElement is an element of FqMont, in Montgomery form (a * 2^256 mod Q), stored in 4 limbs of 64 bits in little-endian order
+type MontElement [4]uint64
+
+// FqMont is the Z field over modulus Q, with the elements represented in Montgomery form with fixed size limbs. Q must be odd and smaller than 2^255, as the BN128 base and scalar fields
+type FqMont struct {
+	Q    *big.Int
+	q    MontElement // Q in limbs (not in Montgomery form)
+	qInv uint64      // -Q^-1 mod 2^64
+	r2   MontElement // 2^512 mod Q, used to convert to Montgomery form
+	one  MontElement // 2^256 mod Q, the One in Montgomery form
+}
+
+// limbsFromBig returns the limbs of a, which must be positive and smaller than 2^256
+func limbsFromBig(a *big.Int) MontElement {
+	var r MontElement
+	words := a.Bits()
+	if bits.UintSize == 64 {
+		for i := 0; i < len(words) && i < 4; i++ {
+			r[i] = uint64(words[i])
+		}
+		return r
+	}
+	for i := 0; i < len(words) && i < 8; i++ {
+		r[i/2] |= uint64(words[i]) << (32 * uint(i%2))
+	}
+	return r
+}
+
+// limbsToBig returns the big.Int value of the limbs
+func limbsToBig(a MontElement) *big.Int {
+	if a[0]|a[1]|a[2]|a[3] == 0 {
+		return new(big.Int)
+	}
+	if bits.UintSize == 64 {
+		words := make([]big.Word, 4)
+		for i := 0; i < 4; i++ {
+			words[i] = big.Word(a[i])
+		}
+		return new(big.Int).SetBits(words)
+	}
+	words := make([]big.Word, 8)
+	for i := 0; i < 8; i++ {
+		words[i] = big.Word(a[i/2] >> (32 * uint(i%2)))
+	}
+	return new(big.Int).SetBits(words)
+}
+
+// NewFqMont generates a new FqMont
+func NewFqMont(q *big.Int) (FqMont, error) {
+	if q.Sign() <= 0 || q.Bit(0) == 0 || q.BitLen() > 255 {
+		return FqMont{}, errors.New("modulus must be odd and smaller than 2^255")
+	}
+	var f FqMont
+	f.Q = q
+	f.q = limbsFromBig(q)
+
+	two64 := new(big.Int).Lsh(big.NewInt(int64(1)), 64)
+	inv := new(big.Int).ModInverse(new(big.Int).Mod(q, two64), two64)
+	f.qInv = -inv.Uint64()
+
+	r := new(big.Int).Lsh(big.NewInt(int64(1)), 256)
+	f.one = limbsFromBig(new(big.Int).Mod(r, q))
+	f.r2 = limbsFromBig(new(big.Int).Mod(new(big.Int).Mul(r, r), q))
+	return f, nil
+}
+
+// inRange returns true if a is in [0, Q)
+func (f FqMont) inRange(a *big.Int) bool {
+	return a.Sign() >= 0 && a.Cmp(f.Q) < 0
+}
+
+// FromBig converts a big.Int value to Montgomery form
+func (f FqMont) FromBig(a *big.Int) MontElement {
+	if !f.inRange(a) {
+		a = new(big.Int).Mod(a, f.Q)
+	}
+	return f.Mul(limbsFromBig(a), f.r2)
+}
+
+// ToBig converts a value in Montgomery form to big.Int
+func (f FqMont) ToBig(a MontElement) *big.Int {
+	return limbsToBig(f.Mul(a, MontElement{1, 0, 0, 0}))
+}
+
+// Zero returns a Zero value on the FqMont
+func (f FqMont) Zero() MontElement {
+	return MontElement{}
+}
+
+// One returns a One value on the FqMont
+func (f FqMont) One() MontElement {
+	return f.one
+}
+
+// reduce subtracts Q from a if a >= Q, being carry the bit 256 of a
+func (f FqMont) reduce(a MontElement, carry uint64) MontElement {
+	var r MontElement
+	var borrow uint64
+	r[0], borrow = bits.Sub64(a[0], f.q[0], 0)
+	r[1], borrow = bits.Sub64(a[1], f.q[1], borrow)
+	r[2], borrow = bits.Sub64(a[2], f.q[2], borrow)
+	r[3], borrow = bits.Sub64(a[3], f.q[3], borrow)
+	if carry == 0 && borrow != 0 {
+		// a < Q
+		return a
+	}
+	return r
+}
+
+// Add performs an addition on the FqMont
+func (f FqMont) Add(a, b MontElement) MontElement {
+	var r MontElement
+	var carry uint64
+	r[0], carry = bits.Add64(a[0], b[0], 0)
+	r[1], carry = bits.Add64(a[1], b[1], carry)
+	r[2], carry = bits.Add64(a[2], b[2], carry)
+	r[3], carry = bits.Add64(a[3], b[3], carry)
+	return f.reduce(r, carry)
+}
+
+// Double performs a doubling on the FqMont
+func (f FqMont) Double(a MontElement) MontElement {
+	return f.Add(a, a)
+}
+
+// Sub performs a subtraction on the FqMont
+func (f FqMont) Sub(a, b MontElement) MontElement {
+	var r MontElement
+	var borrow uint64
+	r[0], borrow = bits.Sub64(a[0], b[0], 0)
+	r[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	r[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	r[3], borrow = bits.Sub64(a[3], b[3], borrow)
+	if borrow != 0 {
+		var carry uint64
+		r[0], carry = bits.Add64(r[0], f.q[0], 0)
+		r[1], carry = bits.Add64(r[1], f.q[1], carry)
+		r[2], carry = bits.Add64(r[2], f.q[2], carry)
+		r[3], _ = bits.Add64(r[3], f.q[3], carry)
+	}
+	return r
+}
+
+// Neg performs a negation on the FqMont
+func (f FqMont) Neg(a MontElement) MontElement {
+	return f.Sub(MontElement{}, a)
+}
+
+// Mul performs a Montgomery multiplication on the FqMont, a * b * 2^-256 mod Q, which is the product of the elements in Montgomery form
+func (f FqMont) Mul(a, b MontElement) MontElement {
+	// CIOS (Coarsely Integrated Operand Scanning) method
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		// t += a * b[i]
+		var c, hi, lo, carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo = bits.Mul64(a[j], b[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j] = lo
+			c = hi
+		}
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		// t = (t + m * Q) / 2^64, where m makes t + m * Q divisible by 2^64
+		m := t[0] * f.qInv
+		hi, lo = bits.Mul64(m, f.q[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, f.q[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1] = lo
+			c = hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	return f.reduce(MontElement{t[0], t[1], t[2], t[3]}, t[4])
+}
+
+// Square performs a square operation on the FqMont
+func (f FqMont) Square(a MontElement) MontElement {
+	return f.Mul(a, a)
+}
+
+// Exp performs the exponential over FqMont, e must be positive
+func (f FqMont) Exp(base MontElement, e *big.Int) MontElement {
+	res := f.one
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = f.Square(res)
+		if e.Bit(i) == 1 {
+			res = f.Mul(res, base)
+		}
+	}
+	return res
+}
+
+// Inverse returns the inverse on the FqMont, using the Fermat's little theorem a^(Q-2) = a^-1
+func (f FqMont) Inverse(a MontElement) MontElement {
+	return f.Exp(a, new(big.Int).Sub(f.Q, big.NewInt(int64(2))))
+}
+
+// Div performs the division over the FqMont
+func (f FqMont) Div(a, b MontElement) MontElement {
+	return f.Mul(a, f.Inverse(b))
+}
+
+// IsZero returns true if the element is zero
+func (f FqMont) IsZero(a MontElement) bool {
+	return a[0]|a[1]|a[2]|a[3] == 0
+}
+
+// Equal returns true if both elements are equal
+func (f FqMont) Equal(a, b MontElement) bool {
+	return a == b
+}
@@ -0,0 +1,81 @@
+package fields
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFqMont(t *testing.T) {
+	q, ok := new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10) // BN128 base field
+	assert.True(t, ok)
+	fm, err := NewFqMont(q)
+	assert.Nil(t, err)
+	fb := NewFqBigInt(q)
+
+	for i := 0; i < 100; i++ {
+		a, err := fb.Rand()
+		assert.Nil(t, err)
+		b, err := fb.Rand()
+		assert.Nil(t, err)
+		if i == 0 {
+			a = big.NewInt(int64(0))
+		}
+		if i == 1 {
+			b = new(big.Int).Sub(q, big.NewInt(int64(1)))
+		}
+		am := fm.FromBig(a)
+		bm := fm.FromBig(b)
+
+		assert.Equal(t, a, fm.ToBig(am))
+		assert.Equal(t, fb.Add(a, b), fm.ToBig(fm.Add(am, bm)))
+		assert.Equal(t, fb.Double(a), fm.ToBig(fm.Double(am)))
+		assert.Equal(t, fb.Sub(a, b), fm.ToBig(fm.Sub(am, bm)))
+		assert.Equal(t, fb.Neg(a), fm.ToBig(fm.Neg(am)))
+		assert.Equal(t, fb.Mul(a, b), fm.ToBig(fm.Mul(am, bm)))
+		assert.Equal(t, fb.Square(a), fm.ToBig(fm.Square(am)))
+		assert.Equal(t, fb.Exp(a, b), fm.ToBig(fm.Exp(am, b)))
+		if !fb.IsZero(b) {
+			assert.Equal(t, fb.Inverse(b), fm.ToBig(fm.Inverse(bm)))
+			assert.Equal(t, fb.Div(a, b), fm.ToBig(fm.Div(am, bm)))
+		}
+	}
+	assert.True(t, fm.IsZero(fm.Zero()))
+	assert.Equal(t, big.NewInt(int64(1)), fm.ToBig(fm.One()))
+
+	// even modulus is not supported
+	_, err = NewFqMont(big.NewInt(int64(10)))
+	assert.NotNil(t, err)
+}
+
+func TestFqBackends(t *testing.T) {
+	r, ok := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	assert.True(t, ok)
+	fq := NewFq(r)
+	assert.NotNil(t, fq.mont)
+	fb := NewFqBigInt(r)
+
+	vals := []*big.Int{
+		big.NewInt(int64(0)),
+		big.NewInt(int64(1)),
+		big.NewInt(int64(-5)),                     // negative values
+		new(big.Int).Add(r, big.NewInt(int64(3))), // values bigger than the modulus
+	}
+	for i := 0; i < 20; i++ {
+		v, err := fb.Rand()
+		assert.Nil(t, err)
+		vals = append(vals, v)
+	}
+	for _, a := range vals {
+		for _, b := range vals {
+			assert.Equal(t, fb.Add(a, b), fq.Add(a, b))
+			assert.Equal(t, fb.Sub(a, b), fq.Sub(a, b))
+			assert.Equal(t, fb.Mul(a, b), fq.Mul(a, b))
+		}
+		assert.Equal(t, fb.Neg(a), fq.Neg(a))
+		assert.Equal(t, fb.Double(a), fq.Double(a))
+		assert.Equal(t, fb.Square(a), fq.Square(a))
+		assert.Equal(t, fb.Exp(a, big.NewInt(int64(12345))), fq.Exp(a, big.NewInt(int64(12345))))
+	}
+}
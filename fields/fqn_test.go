@@ -42,6 +42,12 @@ func TestFq1(t *testing.T) {
 
 	res = fq1.Square(iToBig(5))
 	assert.Equal(t, iToBig(4), res)
+
+	res, ok := fq1.Sqrt(iToBig(4))
+	assert.True(t, ok)
+	assert.True(t, fq1.Equal(iToBig(4), fq1.Square(res)))
+	_, ok = fq1.Sqrt(iToBig(3))
+	assert.True(t, !ok)
 }
 
 func TestFq2(t *testing.T) {
@@ -81,6 +87,25 @@ func TestFq2(t *testing.T) {
 	assert.Equal(t, iiToBig(5, 2), fq2.Affine(res))
 	res2 = fq2.Mul(iiToBig(3, 5), iiToBig(3, 5))
 	assert.Equal(t, fq2.Affine(res), fq2.Affine(res2))
+
+	res = fq2.Exp(iiToBig(3, 5), iToBig(3))
+	assert.Equal(t, fq2.Affine(fq2.Mul(res2, iiToBig(3, 5))), fq2.Affine(res))
+
+	// all the squares have a square root, and half of the non zero elements are squares
+	squares := 0
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			a := iiToBig(i, j)
+			r, ok := fq2.Sqrt(fq2.Square(a))
+			assert.True(t, ok)
+			assert.True(t, fq2.Equal(fq2.Square(a), fq2.Square(r)))
+			if r, ok := fq2.Sqrt(a); ok {
+				assert.True(t, fq2.Equal(a, fq2.Square(r)))
+				squares++
+			}
+		}
+	}
+	assert.Equal(t, 1+48/2, squares)
 }
 
 func TestFq6(t *testing.T) {
@@ -3,13 +3,16 @@
 package groth16
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
 
 	"github.com/arnaucube/go-snark-study/bn128"
 	"github.com/arnaucube/go-snark-study/circuitcompiler"
 	"github.com/arnaucube/go-snark-study/fields"
 	"github.com/arnaucube/go-snark-study/r1csqap"
+	"github.com/arnaucube/go-snark-study/utils/parallel"
 )
 
 type Pk struct { // Proving Key
@@ -64,6 +67,82 @@ type Proof struct {
 	PiC [3]*big.Int
 }
 
+// ProofSize is the size in bytes of the binary encoding of the Proof
+const ProofSize = bn128.G1CompressedSize + bn128.G2CompressedSize + bn128.G1CompressedSize
+
+// MarshalBinary encodes the proof in ProofSize bytes, with the compressed encodings of PiA, PiB and PiC
+func (proof Proof) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, ProofSize)
+	b = append(b, Utils.Bn.G1.MarshalCompressed(proof.PiA)...)
+	b = append(b, Utils.Bn.G2.MarshalCompressed(proof.PiB)...)
+	b = append(b, Utils.Bn.G1.MarshalCompressed(proof.PiC)...)
+	return b, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary, returning an error if some of its points is not valid
+func (proof *Proof) UnmarshalBinary(b []byte) error {
+	if len(b) != ProofSize {
+		return fmt.Errorf("invalid proof length %d, expected %d", len(b), ProofSize)
+	}
+	var p Proof
+	var err error
+	p.PiA, err = Utils.Bn.G1.Unmarshal(b[:bn128.G1CompressedSize])
+	if err != nil {
+		return err
+	}
+	b = b[bn128.G1CompressedSize:]
+	p.PiB, err = Utils.Bn.G2.Unmarshal(b[:bn128.G2CompressedSize])
+	if err != nil {
+		return err
+	}
+	b = b[bn128.G2CompressedSize:]
+	p.PiC, err = Utils.Bn.G1.Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	if err = ValidateProof(p); err != nil {
+		return err
+	}
+	*proof = p
+	return nil
+}
+
+// ValidateProof returns an error if some point of the proof is not a valid point of its group
+func ValidateProof(proof Proof) error {
+	if err := Utils.Bn.CheckG1(proof.PiA); err != nil {
+		return fmt.Errorf("invalid proof PiA: %s", err)
+	}
+	if err := Utils.Bn.CheckG2(proof.PiB); err != nil {
+		return fmt.Errorf("invalid proof PiB: %s", err)
+	}
+	if err := Utils.Bn.CheckG1(proof.PiC); err != nil {
+		return fmt.Errorf("invalid proof PiC: %s", err)
+	}
+	return nil
+}
+
+// ValidateVk returns an error if some point of the verification key is not a valid point of its group
+func ValidateVk(vk Vk) error {
+	for i := 0; i < len(vk.IC); i++ {
+		if err := Utils.Bn.CheckG1(vk.IC[i]); err != nil {
+			return fmt.Errorf("invalid vk IC[%d]: %s", i, err)
+		}
+	}
+	if err := Utils.Bn.CheckG1(vk.G1.Alpha); err != nil {
+		return fmt.Errorf("invalid vk G1.Alpha: %s", err)
+	}
+	if err := Utils.Bn.CheckG2(vk.G2.Beta); err != nil {
+		return fmt.Errorf("invalid vk G2.Beta: %s", err)
+	}
+	if err := Utils.Bn.CheckG2(vk.G2.Gamma); err != nil {
+		return fmt.Errorf("invalid vk G2.Gamma: %s", err)
+	}
+	if err := Utils.Bn.CheckG2(vk.G2.Delta); err != nil {
+		return fmt.Errorf("invalid vk G2.Delta: %s", err)
+	}
+	return nil
+}
+
 type utils struct {
 	Bn  bn128.Bn128
 	FqR fields.Fq
@@ -118,35 +197,30 @@ func GenerateTrustedSetup(witnessLength int, circuit circuitcompiler.Circuit, al
 		return Setup{}, err
 	}
 
-	// z pol
-	zpol := []*big.Int{big.NewInt(int64(1))}
-	for i := 1; i < len(alphas)-1; i++ {
-		zpol = Utils.PF.Mul(
-			zpol,
-			[]*big.Int{
-				Utils.FqR.Neg(
-					big.NewInt(int64(i))),
-				big.NewInt(int64(1)),
-			})
+	// z pol: x^n - 1, vanishing over the domain where the QAP polynomials have been interpolated
+	domain, err := Utils.PF.NewDomain(len(alphas[0]))
+	if err != nil {
+		return Setup{}, err
 	}
+	zpol := Utils.PF.VanishingPolynomial(domain)
 	setup.Pk.Z = zpol
 	zt := Utils.PF.Eval(zpol, setup.Toxic.T)
 	invDelta := Utils.FqR.Inverse(setup.Toxic.Kdelta)
 	ztinvDelta := Utils.FqR.Mul(invDelta, zt)
 
+	workers := runtime.NumCPU()
+
 	// encrypt t values with curve generators
 	// powers of tau divided by delta
-	var ptd [][3]*big.Int
-	ini := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, ztinvDelta)
-	ptd = append(ptd, ini)
+	tds := []*big.Int{ztinvDelta}
 	tEncr := setup.Toxic.T
 	for i := 1; i < len(zpol); i++ {
-		ptd = append(ptd, Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, Utils.FqR.Mul(tEncr, ztinvDelta)))
+		tds = append(tds, Utils.FqR.Mul(tEncr, ztinvDelta))
 		tEncr = Utils.FqR.Mul(tEncr, setup.Toxic.T)
 	}
 	// powers of τ encrypted in G1 curve, divided by δ
 	// (G1 * τ) / δ
-	setup.Pk.PowersTauDelta = ptd
+	setup.Pk.PowersTauDelta = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, tds, workers)
 
 	setup.Pk.G1.Alpha = Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, setup.Toxic.Kalpha)
 	setup.Pk.G1.Beta = Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, setup.Toxic.Kbeta)
@@ -159,75 +233,79 @@ func GenerateTrustedSetup(witnessLength int, circuit circuitcompiler.Circuit, al
 	setup.Vk.G2.Gamma = Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, setup.Toxic.Kgamma)
 	setup.Vk.G2.Delta = Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, setup.Toxic.Kdelta)
 
-	for i := 0; i < len(circuit.Signals); i++ {
-		// Pk.G1.At: {a(τ)} from 0 to m
-		at := Utils.PF.Eval(alphas[i], setup.Toxic.T)
-		a := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, at)
-		setup.Pk.G1.At = append(setup.Pk.G1.At, a)
+	// a(τ), b(τ), c(τ) of all the signals, evaluated once. If the circuit has the R1CS they are evaluated with the Lagrange basis of the domain, which is much faster than evaluating the QAP polynomials
+	var at, bt, ct []*big.Int
+	if len(circuit.R1CS.A) > 0 {
+		at, bt, ct, err = Utils.PF.QAPEvalAt(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C, setup.Toxic.T, workers)
+		if err != nil {
+			return Setup{}, err
+		}
+	} else {
+		at = Utils.PF.BatchEval(alphas, setup.Toxic.T, workers)
+		bt = Utils.PF.BatchEval(betas, setup.Toxic.T, workers)
+		ct = Utils.PF.BatchEval(gammas, setup.Toxic.T, workers)
+	}
+
+	// Pk.G1.At: {a(τ)} from 0 to m
+	setup.Pk.G1.At = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, at, workers)
+	// G1.BACGamma: {( βui(x)+αvi(x)+wi(x) ) / γ } from 0 to m in G1
+	setup.Pk.G1.BACGamma = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, bt, workers)
+	// G2.BACGamma: {( βui(x)+αvi(x)+wi(x) ) / γ } from 0 to m in G2
+	setup.Pk.G2.BACGamma = Utils.Bn.G2.BatchMulScalar(Utils.Bn.G2.G, bt, workers)
 
-		bt := Utils.PF.Eval(betas[i], setup.Toxic.T)
-		g1bt := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, bt)
-		g2bt := Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, bt)
-		// G1.BACGamma: {( βui(x)+αvi(x)+wi(x) ) / γ } from 0 to m in G1
-		setup.Pk.G1.BACGamma = append(setup.Pk.G1.BACGamma, g1bt)
-		// G2.BACGamma: {( βui(x)+αvi(x)+wi(x) ) / γ } from 0 to m in G2
-		setup.Pk.G2.BACGamma = append(setup.Pk.G2.BACGamma, g2bt)
+	// ( βui(x)+αvi(x)+wi(x) )
+	bac := func(i int) *big.Int {
+		return Utils.FqR.Add(
+			Utils.FqR.Add(
+				Utils.FqR.Mul(at[i], setup.Toxic.Kbeta),
+				Utils.FqR.Mul(bt[i], setup.Toxic.Kalpha),
+			),
+			ct[i],
+		)
 	}
 
 	zero3 := [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
 	for i := 0; i < circuit.NPublic+1; i++ {
 		setup.Pk.BACDelta = append(setup.Pk.BACDelta, zero3)
 	}
+	var cs []*big.Int
 	for i := circuit.NPublic + 1; i < circuit.NVars; i++ {
-		// TODO calculate all at, bt, ct outside, to avoid repeating calculations
-		at := Utils.PF.Eval(alphas[i], setup.Toxic.T)
-		bt := Utils.PF.Eval(betas[i], setup.Toxic.T)
-		ct := Utils.PF.Eval(gammas[i], setup.Toxic.T)
-		c := Utils.FqR.Mul(
-			invDelta,
-			Utils.FqR.Add(
-				Utils.FqR.Add(
-					Utils.FqR.Mul(at, setup.Toxic.Kbeta),
-					Utils.FqR.Mul(bt, setup.Toxic.Kalpha),
-				),
-				ct,
-			),
-		)
-		g1c := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, c)
-
-		// Pk.BACDelta: {( βui(x)+αvi(x)+wi(x) ) / δ } from l+1 to m
-		setup.Pk.BACDelta = append(setup.Pk.BACDelta, g1c)
+		cs = append(cs, Utils.FqR.Mul(invDelta, bac(i)))
 	}
+	// Pk.BACDelta: {( βui(x)+αvi(x)+wi(x) ) / δ } from l+1 to m
+	setup.Pk.BACDelta = append(setup.Pk.BACDelta, Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, cs, workers)...)
 
+	invGamma := Utils.FqR.Inverse(setup.Toxic.Kgamma)
+	var ics []*big.Int
 	for i := 0; i <= circuit.NPublic; i++ {
-		at := Utils.PF.Eval(alphas[i], setup.Toxic.T)
-		bt := Utils.PF.Eval(betas[i], setup.Toxic.T)
-		ct := Utils.PF.Eval(gammas[i], setup.Toxic.T)
-		ic := Utils.FqR.Mul(
-			Utils.FqR.Inverse(setup.Toxic.Kgamma),
-			Utils.FqR.Add(
-				Utils.FqR.Add(
-					Utils.FqR.Mul(at, setup.Toxic.Kbeta),
-					Utils.FqR.Mul(bt, setup.Toxic.Kalpha),
-				),
-				ct,
-			),
-		)
-		g1ic := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, ic)
-		// used in verifier
-		setup.Vk.IC = append(setup.Vk.IC, g1ic)
+		ics = append(ics, Utils.FqR.Mul(invGamma, bac(i)))
 	}
+	// used in verifier
+	setup.Vk.IC = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, ics, workers)
 
 	return setup, nil
 }
 
-// GenerateProofs generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness
-func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, px []*big.Int) (Proof, error) {
-	var proof Proof
-	proof.PiA = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiB = Utils.Bn.Fq6.Zero()
-	proof.PiC = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
+// ProverOptions are the options used by the prover
+type ProverOptions struct {
+	Workers int // number of goroutines used by the prover, if is 0 all the CPUs are used
+}
 
+// workers returns the number of goroutines to use
+func (opts ProverOptions) workers() int {
+	if opts.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return opts.Workers
+}
+
+// GenerateProofs generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness, using all the CPUs
+func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int) (Proof, error) {
+	return GenerateProofsWithOptions(circuit, pk, w, ProverOptions{})
+}
+
+// GenerateProofsWithOptions generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness, with the given ProverOptions. The Proof is the same for any number of workers
+func GenerateProofsWithOptions(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, opts ProverOptions) (Proof, error) {
 	r, err := Utils.FqR.Rand()
 	if err != nil {
 		return Proof{}, err
@@ -236,17 +314,45 @@ func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, px []*
 	if err != nil {
 		return Proof{}, err
 	}
+	return generateProofs(circuit, pk, w, r, s, opts)
+}
+
+// generateProofs generates the Proof with the given random values r and s
+func generateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, r, s *big.Int, opts ProverOptions) (Proof, error) {
+	var proof Proof
+	workers := opts.workers()
 
 	// piBG1 will hold all the same than proof.PiB but in G1 curve
-	piBG1 := [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
+	var piBG1 [3]*big.Int
+	var hx []*big.Int
+	var piH [3]*big.Int
+	var err error
 
-	for i := 0; i < circuit.NVars; i++ {
-		proof.PiA = Utils.Bn.G1.Add(proof.PiA, Utils.Bn.G1.MulScalar(pk.G1.At[i], w[i]))
-		piBG1 = Utils.Bn.G1.Add(piBG1, Utils.Bn.G1.MulScalar(pk.G1.BACGamma[i], w[i]))
-		proof.PiB = Utils.Bn.G2.Add(proof.PiB, Utils.Bn.G2.MulScalar(pk.G2.BACGamma[i], w[i]))
-	}
-	for i := circuit.NPublic + 1; i < circuit.NVars; i++ {
-		proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(pk.BACDelta[i], w[i]))
+	// the multi-scalar multiplications and h(x) are independent between them
+	parallel.RunTasks(workers,
+		func(workers int) {
+			proof.PiA = Utils.Bn.G1.MultiExpParallel(pk.G1.At[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			piBG1 = Utils.Bn.G1.MultiExpParallel(pk.G1.BACGamma[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiB = Utils.Bn.G2.MultiExpParallel(pk.G2.BACGamma[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiC = Utils.Bn.G1.MultiExpParallel(pk.BACDelta[circuit.NPublic+1:circuit.NVars], w[circuit.NPublic+1:circuit.NVars], workers)
+		},
+		func(workers int) {
+			// h(x) = (a(x) * b(x) - c(x)) / z(x), computed from the R1CS and the witness over a coset of the domain
+			hx, err = Utils.PF.HFromWitnessParallel(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C, w, workers)
+			if err != nil {
+				return
+			}
+			piH = Utils.Bn.G1.MultiExpParallel(pk.PowersTauDelta[:len(hx)], hx, workers)
+		},
+	)
+	if err != nil {
+		return Proof{}, err
 	}
 
 	// piA = (Σ from 0 to m (pk.A * w[i])) + pk.Alpha1 + r * δ
@@ -263,35 +369,113 @@ func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, px []*
 	deltaSG2 := Utils.Bn.G2.MulScalar(pk.G2.Delta, s)
 	proof.PiB = Utils.Bn.G2.Add(proof.PiB, deltaSG2)
 
-	hx := Utils.PF.DivisorPolynomial(px, pk.Z) // maybe move this calculation to a previous step
-
 	// piC = (Σ from l+1 to m (w[i] * (pk.g1.Beta + pk.g1.Alpha + pk.C)) + h(tau)) / δ) + piA*s + r*piB - r*s*δ
-	for i := 0; i < len(hx); i++ {
-		proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(pk.PowersTauDelta[i], hx[i]))
-	}
+	proof.PiC = Utils.Bn.G1.Add(proof.PiC, piH)
 	proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(proof.PiA, s))
 	proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(piBG1, r))
 	negRS := Utils.FqR.Neg(Utils.FqR.Mul(r, s))
 	proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(pk.G1.Delta, negRS))
 
+	proof.PiA = Utils.Bn.G1.Normalize(proof.PiA)
+	proof.PiB = Utils.Bn.G2.Affine(proof.PiB)
+	proof.PiC = Utils.Bn.G1.Normalize(proof.PiC)
 	return proof, nil
 }
 
-// VerifyProof verifies over the BN128 the Pairings of the Proof
-func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool {
-
+// publicInputsCommitment returns IC[0] + Σ IC[i+1] * publicSignals[i], or an error if there is not a public signal for each IC[i+1]
+func publicInputsCommitment(vk Vk, publicSignals []*big.Int) ([3]*big.Int, error) {
+	if len(publicSignals) != len(vk.IC)-1 {
+		return [3]*big.Int{}, fmt.Errorf("expected %d public signals, got %d", len(vk.IC)-1, len(publicSignals))
+	}
 	icPubl := vk.IC[0]
 	for i := 0; i < len(publicSignals); i++ {
 		icPubl = Utils.Bn.G1.Add(icPubl, Utils.Bn.G1.MulScalar(vk.IC[i+1], publicSignals[i]))
 	}
+	return icPubl, nil
+}
+
+// VerifyProof verifies over the BN128 the Pairings of the Proof
+func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool {
+	if err := ValidateProof(proof); err != nil {
+		if debug {
+			fmt.Println("❌ groth16 verification not passed,", err)
+		}
+		return false
+	}
+
+	icPubl, err := publicInputsCommitment(vk, publicSignals)
+	if err != nil {
+		if debug {
+			fmt.Println("❌ groth16 verification not passed,", err)
+		}
+		return false
+	}
+
+	// e(piA, piB) == e(alpha, beta) * e(ic, gamma) * e(piC, delta), checked as
+	// e(-piA, piB) * e(alpha, beta) * e(ic, gamma) * e(piC, delta) == 1
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{Utils.Bn.G1.Neg(proof.PiA), vk.G1.Alpha, icPubl, proof.PiC},
+		[][3][2]*big.Int{proof.PiB, vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta}) {
+		if debug {
+			fmt.Println("❌ groth16 verification not passed")
+		}
+		return false
+	}
+	if debug {
+		fmt.Println("✓ groth16 verification passed")
+	}
+
+	return true
+}
+
+// PreparedVk is the Vk together with the values of the verification that do not depend on the proof, to verify many proofs with the same Vk
+type PreparedVk struct {
+	Vk        Vk
+	AlphaBeta [2][3][2]*big.Int  // e(alpha, beta)
+	Gamma     bn128.AteG2Precomp // lines of the Miller loop for vk.G2.Gamma
+	Delta     bn128.AteG2Precomp // lines of the Miller loop for vk.G2.Delta
+}
+
+// PrepareVk computes the PreparedVk of the Vk
+func PrepareVk(vk Vk) PreparedVk {
+	return PreparedVk{
+		Vk:        vk,
+		AlphaBeta: Utils.Bn.Pairing(vk.G1.Alpha, vk.G2.Beta),
+		Gamma:     Utils.Bn.PreComputeG2(vk.G2.Gamma),
+		Delta:     Utils.Bn.PreComputeG2(vk.G2.Delta),
+	}
+}
+
+// VerifyProofPrepared verifies the Proof as VerifyProof, but using the values precomputed in the PreparedVk
+func VerifyProofPrepared(pvk PreparedVk, proof Proof, publicSignals []*big.Int, debug bool) bool {
+	if err := ValidateProof(proof); err != nil {
+		if debug {
+			fmt.Println("❌ groth16 verification not passed,", err)
+		}
+		return false
+	}
+
+	if Utils.Bn.G1.IsZero(proof.PiA) || Utils.Bn.G2.IsZero(proof.PiB) {
+		// e(piA, piB) would be 1, as in MultiPairing, and the zero piB can not be precomputed
+		if debug {
+			fmt.Println("❌ groth16 verification not passed, piA or piB is zero")
+		}
+		return false
+	}
 
-	if !Utils.Bn.Fq12.Equal(
-		Utils.Bn.Pairing(proof.PiA, proof.PiB),
-		Utils.Bn.Fq12.Mul(
-			Utils.Bn.Pairing(vk.G1.Alpha, vk.G2.Beta),
-			Utils.Bn.Fq12.Mul(
-				Utils.Bn.Pairing(icPubl, vk.G2.Gamma),
-				Utils.Bn.Pairing(proof.PiC, vk.G2.Delta)))) {
+	icPubl, err := publicInputsCommitment(pvk.Vk, publicSignals)
+	if err != nil {
+		if debug {
+			fmt.Println("❌ groth16 verification not passed,", err)
+		}
+		return false
+	}
+
+	// e(-piA, piB) * e(ic, gamma) * e(piC, delta) * e(alpha, beta) == 1
+	mp := Utils.Bn.MultiPairingPrecomputed(
+		[][3]*big.Int{Utils.Bn.G1.Neg(proof.PiA), icPubl, proof.PiC},
+		[]bn128.AteG2Precomp{Utils.Bn.PreComputeG2(proof.PiB), pvk.Gamma, pvk.Delta})
+	if !Utils.Bn.Fq12.Equal(Utils.Bn.Fq12.Mul(mp, pvk.AlphaBeta), Utils.Bn.Fq12.One()) {
 		if debug {
 			fmt.Println("❌ groth16 verification not passed")
 		}
@@ -303,3 +487,63 @@ func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool
 
 	return true
 }
+
+// BatchVerify verifies many proofs with the same Vk, combining them with random scalars r_i into a single pairing check:
+// Π e(-r_i * piA_i, piB_i) * e(Σ r_i * ic_i, gamma) * e(Σ r_i * piC_i, delta) * e(Σ r_i * alpha, beta) == 1
+// The proofs with invalid points or with a number of public signals different from the Vk are not included in the combined check. If the check does not pass, the proofs are verified one by one. Returns the indexes of the proofs that are not valid
+func BatchVerify(vk Vk, proofs []Proof, publicSignals [][]*big.Int) ([]int, error) {
+	if len(proofs) != len(publicSignals) {
+		return nil, errors.New("different number of proofs and public signals")
+	}
+	if len(proofs) == 0 {
+		return []int{}, nil
+	}
+
+	var g1s [][3]*big.Int
+	var g2s [][3][2]*big.Int
+	var rs []*big.Int
+	var icPubls, piCs [][3]*big.Int
+	rSum := Utils.FqR.Zero()
+	invalid := make([]bool, len(proofs))
+	for i := 0; i < len(proofs); i++ {
+		if ValidateProof(proofs[i]) != nil {
+			invalid[i] = true
+			continue
+		}
+		icPubl, err := publicInputsCommitment(vk, publicSignals[i])
+		if err != nil {
+			invalid[i] = true
+			continue
+		}
+		r, err := Utils.FqR.Rand()
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+		rSum = Utils.FqR.Add(rSum, r)
+
+		g1s = append(g1s, Utils.Bn.G1.Neg(Utils.Bn.G1.MulScalar(proofs[i].PiA, r)))
+		g2s = append(g2s, proofs[i].PiB)
+		icPubls = append(icPubls, icPubl)
+		piCs = append(piCs, proofs[i].PiC)
+	}
+	g1s = append(g1s,
+		Utils.Bn.G1.MultiExp(icPubls, rs),
+		Utils.Bn.G1.MultiExp(piCs, rs),
+		Utils.Bn.G1.MulScalar(vk.G1.Alpha, rSum))
+	g2s = append(g2s, vk.G2.Gamma, vk.G2.Delta, vk.G2.Beta)
+	passed := Utils.Bn.PairingCheck(g1s, g2s)
+
+	// if the combined check does not pass, some proof is not valid, find which ones
+	var pvk PreparedVk
+	if !passed {
+		pvk = PrepareVk(vk)
+	}
+	failed := []int{}
+	for i := 0; i < len(proofs); i++ {
+		if invalid[i] || (!passed && !VerifyProofPrepared(pvk, proofs[i], publicSignals[i], false)) {
+			failed = append(failed, i)
+		}
+	}
+	return failed, nil
+}
@@ -52,7 +52,8 @@ func TestGroth16MinimalFlow(t *testing.T) {
 
 	// R1CS to QAP
 	// TODO zxQAP is not used and is an old impl, TODO remove
-	alphas, betas, gammas, _ := Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, _, err := Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	fmt.Println("qap")
 	assert.Equal(t, 8, len(alphas))
 	assert.Equal(t, 8, len(alphas))
@@ -60,10 +61,10 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	assert.True(t, !bytes.Equal(alphas[1][1].Bytes(), big.NewInt(int64(0)).Bytes()))
 
 	ax, bx, cx, px := Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	assert.Equal(t, 7, len(ax))
-	assert.Equal(t, 7, len(bx))
-	assert.Equal(t, 7, len(cx))
-	assert.Equal(t, 13, len(px))
+	assert.Equal(t, 8, len(ax))
+	assert.Equal(t, 8, len(bx))
+	assert.Equal(t, 8, len(cx))
+	assert.Equal(t, 15, len(px))
 
 	// ---
 	// from here is the GROTH16
@@ -77,7 +78,7 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	hx := Utils.PF.DivisorPolynomial(px, setup.Pk.Z)
 	div, rem := Utils.PF.Div(px, setup.Pk.Z)
 	assert.Equal(t, hx, div)
-	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(6))
+	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(8))
 
 	// hx==px/zx so px==hx*zx
 	assert.Equal(t, px, Utils.PF.Mul(hx, setup.Pk.Z))
@@ -85,9 +86,26 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	// check length of polynomials H(x) and Z(x)
 	assert.Equal(t, len(hx), len(px)-len(setup.Pk.Z)+1)
 
-	proof, err := GenerateProofs(*circuit, setup.Pk, w, px)
+	// h(x) computed by the prover from the witness over the coset
+	hxCoset, err := Utils.PF.HFromWitness(a, b, c, w)
+	assert.Nil(t, err)
+	assert.Equal(t, hx, hxCoset)
+
+	proof, err := GenerateProofs(*circuit, setup.Pk, w)
 	assert.Nil(t, err)
 
+	// the parallel prover gives the same proof than the serial one
+	r, err := Utils.FqR.Rand()
+	assert.Nil(t, err)
+	s, err := Utils.FqR.Rand()
+	assert.Nil(t, err)
+	proofSerial, err := generateProofs(*circuit, setup.Pk, w, r, s, ProverOptions{Workers: 1})
+	assert.Nil(t, err)
+	proofParallel, err := generateProofs(*circuit, setup.Pk, w, r, s, ProverOptions{Workers: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, proofSerial, proofParallel)
+	assert.True(t, VerifyProof(setup.Vk, proofParallel, publicSignals, false))
+
 	// fmt.Println("\n proofs:")
 	// fmt.Println(proof)
 
@@ -104,4 +122,77 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	bOtherWrongPublic := big.NewInt(int64(34))
 	wrongPublicSignalsVerif := []*big.Int{bOtherWrongPublic}
 	assert.True(t, !VerifyProof(setup.Vk, proof, wrongPublicSignalsVerif, false))
+
+	// verification with the PreparedVk
+	pvk := PrepareVk(setup.Vk)
+	assert.True(t, Utils.Bn.Fq12.Equal(Utils.Bn.Pairing(setup.Vk.G1.Alpha, setup.Vk.G2.Beta), pvk.AlphaBeta))
+	assert.True(t, VerifyProofPrepared(pvk, proof, publicSignalsVerif, false))
+	assert.True(t, VerifyProofPrepared(pvk, proofParallel, publicSignalsVerif, false))
+	assert.True(t, !VerifyProofPrepared(pvk, proof, wrongPublicSignalsVerif, false))
+	// proofs with piA or piB at infinity
+	zeroProof := proof
+	zeroProof.PiB = Utils.Bn.G2.Zero()
+	assert.True(t, !VerifyProof(setup.Vk, zeroProof, publicSignalsVerif, false))
+	assert.True(t, !VerifyProofPrepared(pvk, zeroProof, publicSignalsVerif, false))
+	zeroProof = proof
+	zeroProof.PiA = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.One(), Utils.Bn.G1.F.Zero()}
+	assert.True(t, !VerifyProof(setup.Vk, zeroProof, publicSignalsVerif, false))
+	assert.True(t, !VerifyProofPrepared(pvk, zeroProof, publicSignalsVerif, false))
+	// fewer and more public signals than the Vk
+	for _, publicSignals := range [][]*big.Int{{}, {b35Verif, b35Verif}} {
+		assert.True(t, !VerifyProof(setup.Vk, proof, publicSignals, false))
+		assert.True(t, !VerifyProofPrepared(pvk, proof, publicSignals, false))
+	}
+
+	// batch verification
+	proof2, err := GenerateProofs(*circuit, setup.Pk, w)
+	assert.Nil(t, err)
+	proofs := []Proof{proof, proofParallel, proof2}
+	failed, err := BatchVerify(setup.Vk, proofs, [][]*big.Int{publicSignalsVerif, publicSignalsVerif, publicSignalsVerif})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{}, failed)
+	failed, err = BatchVerify(setup.Vk, proofs, [][]*big.Int{publicSignalsVerif, wrongPublicSignalsVerif, publicSignalsVerif})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, failed)
+	_, err = BatchVerify(setup.Vk, proofs, [][]*big.Int{publicSignalsVerif})
+	assert.NotNil(t, err)
+	// proofs with a different number of public signals than the Vk, and with piB at infinity
+	failed, err = BatchVerify(setup.Vk, proofs, [][]*big.Int{publicSignalsVerif, {}, {b35Verif, b35Verif}})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, failed)
+	zeroProof = proof
+	zeroProof.PiB = Utils.Bn.G2.Zero()
+	failed, err = BatchVerify(setup.Vk, []Proof{proof, zeroProof}, [][]*big.Int{publicSignalsVerif, publicSignalsVerif})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, failed)
+
+	// proofs with points out of the curve are rejected
+	assert.Nil(t, ValidateProof(proof))
+	assert.Nil(t, ValidateVk(setup.Vk))
+	invalidProof := proof
+	invalidProof.PiC = [3]*big.Int{proof.PiC[0], Utils.Bn.G1.F.Add(proof.PiC[1], big.NewInt(int64(1))), proof.PiC[2]}
+	assert.NotNil(t, ValidateProof(invalidProof))
+	assert.True(t, !VerifyProof(setup.Vk, invalidProof, publicSignalsVerif, false))
+	assert.True(t, !VerifyProofPrepared(pvk, invalidProof, publicSignalsVerif, false))
+	failed, err = BatchVerify(setup.Vk, []Proof{proof, invalidProof}, [][]*big.Int{publicSignalsVerif, publicSignalsVerif})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, failed)
+	invalidVk := setup.Vk
+	invalidVk.G2.Delta = [3][2]*big.Int{setup.Vk.G2.Delta[0], Utils.Bn.G2.F.Add(setup.Vk.G2.Delta[1], Utils.Bn.G2.F.One()), setup.Vk.G2.Delta[2]}
+	assert.NotNil(t, ValidateVk(invalidVk))
+
+	// binary encoding of the proof
+	proofBytes, err := proof.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Equal(t, 128, len(proofBytes))
+	var decodedProof Proof
+	assert.Nil(t, decodedProof.UnmarshalBinary(proofBytes))
+	assert.True(t, Utils.Bn.G1.Equal(proof.PiA, decodedProof.PiA))
+	assert.True(t, Utils.Bn.G2.Equal(proof.PiB, decodedProof.PiB))
+	assert.True(t, Utils.Bn.G1.Equal(proof.PiC, decodedProof.PiC))
+	assert.True(t, VerifyProof(setup.Vk, decodedProof, publicSignalsVerif, false))
+	assert.NotNil(t, decodedProof.UnmarshalBinary(proofBytes[:127]))
+	proofBytes[0] ^= 0x40 // use the other root for y of PiA
+	assert.Nil(t, decodedProof.UnmarshalBinary(proofBytes))
+	assert.True(t, !VerifyProof(setup.Vk, decodedProof, publicSignalsVerif, false))
 }
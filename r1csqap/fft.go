@@ -0,0 +1,368 @@
+package r1csqap
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// Domain is the evaluation domain formed by the powers of a 2-adic root of unity: {1, ω, ω^2, ..., ω^(N-1)}
+type Domain struct {
+	N        int      // size of the domain, a power of two
+	Omega    *big.Int // primitive N-th root of unity
+	OmegaInv *big.Int // inverse of Omega
+	NInv     *big.Int // inverse of N over the Finite Field
+	CosetGen *big.Int // quadratic non residue, generator of the coset g·{1, ω, ..., ω^(N-1)}
+}
+
+// nttThreshold is the minimum size of the polynomials from which Mul uses the NTT instead of the schoolbook multiplication
+const nttThreshold = 64
+
+// twoAdicity returns s and t such that q-1 = 2^s * t, with t odd
+func twoAdicity(q *big.Int) (uint, *big.Int) {
+	t := new(big.Int).Sub(q, big.NewInt(int64(1)))
+	s := uint(0)
+	for t.Sign() > 0 && t.Bit(0) == 0 {
+		t.Rsh(t, 1)
+		s++
+	}
+	return s, t
+}
+
+// quadraticNonResidue returns the smallest quadratic non residue of the Finite Field
+func (pf PolynomialField) quadraticNonResidue() *big.Int {
+	qMinusOne := new(big.Int).Sub(pf.F.Q, big.NewInt(int64(1)))
+	e := new(big.Int).Rsh(qMinusOne, 1)
+	for g := int64(2); ; g++ {
+		gBig := big.NewInt(g)
+		// Euler's criterion: g^((q-1)/2) == -1 when g is not a square
+		if new(big.Int).Exp(gBig, e, pf.F.Q).Cmp(qMinusOne) == 0 {
+			return gBig
+		}
+	}
+}
+
+// NewDomain returns the smallest Domain of size power of two that contains at least n points
+func (pf PolynomialField) NewDomain(n int) (Domain, error) {
+	size := 1
+	logSize := uint(0)
+	for size < n {
+		size <<= 1
+		logSize++
+	}
+	s, _ := twoAdicity(pf.F.Q)
+	if logSize > s {
+		return Domain{}, errors.New("domain size exceeds the 2-adicity of the Finite Field")
+	}
+
+	// ω = g^((q-1)/size), where g is a quadratic non residue, is a primitive root of unity of order size
+	g := pf.quadraticNonResidue()
+	e := new(big.Int).Sub(pf.F.Q, big.NewInt(int64(1)))
+	e.Rsh(e, logSize)
+	omega := new(big.Int).Exp(g, e, pf.F.Q)
+
+	return Domain{
+		N:        size,
+		Omega:    omega,
+		OmegaInv: pf.F.Inverse(omega),
+		NInv:     pf.F.Inverse(big.NewInt(int64(size))),
+		CosetGen: g,
+	}, nil
+}
+
+// bitReverse reorders the array in bit reversal order of its indexes, len(v) must be a power of two
+func bitReverse(v []*big.Int) {
+	n := len(v)
+	j := 0
+	for i := 1; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			v[i], v[j] = v[j], v[i]
+		}
+	}
+}
+
+// ntt performs the iterative radix-2 Cooley-Tukey transform of v over the powers of omega, where omega is a primitive len(v)-th root of unity
+func (pf PolynomialField) ntt(v []*big.Int, omega *big.Int) {
+	n := len(v)
+	bitReverse(v)
+	for m := 2; m <= n; m <<= 1 {
+		wm := pf.F.Exp(omega, big.NewInt(int64(n/m)))
+		// twiddle factors of this level
+		ws := make([]*big.Int, m/2)
+		ws[0] = pf.F.One()
+		for j := 1; j < m/2; j++ {
+			ws[j] = pf.F.Mul(ws[j-1], wm)
+		}
+		for k := 0; k < n; k += m {
+			for j := 0; j < m/2; j++ {
+				t := pf.F.Mul(ws[j], v[k+j+m/2])
+				u := v[k+j]
+				v[k+j] = pf.F.Add(u, t)
+				v[k+j+m/2] = pf.F.Sub(u, t)
+			}
+		}
+	}
+}
+
+// padToDomain returns a copy of v with d.N elements, filling the missing ones with zeros
+func padToDomain(v []*big.Int, d Domain) []*big.Int {
+	if len(v) > d.N {
+		panic(errors.New("array bigger than the domain size"))
+	}
+	r := make([]*big.Int, d.N)
+	copy(r, v)
+	for i := len(v); i < d.N; i++ {
+		r[i] = big.NewInt(int64(0))
+	}
+	return r
+}
+
+// NTT evaluates the polynomial given by its coefficients over the points of the Domain (Number Theoretic Transform)
+func (pf PolynomialField) NTT(d Domain, coefs []*big.Int) []*big.Int {
+	r := padToDomain(coefs, d)
+	pf.ntt(r, d.Omega)
+	return r
+}
+
+// INTT interpolates the polynomial that takes the given values over the points of the Domain, returning its coefficients (Inverse Number Theoretic Transform)
+func (pf PolynomialField) INTT(d Domain, evals []*big.Int) []*big.Int {
+	r := padToDomain(evals, d)
+	pf.ntt(r, d.OmegaInv)
+	for i := 0; i < len(r); i++ {
+		r[i] = pf.F.Mul(r[i], d.NInv)
+	}
+	return r
+}
+
+// VanishingPolynomial returns the polynomial Z(x) = x^N - 1, which has value zero at all the points of the Domain
+func (pf PolynomialField) VanishingPolynomial(d Domain) []*big.Int {
+	z := ArrayOfBigZeros(d.N + 1)
+	z[0] = pf.F.Neg(big.NewInt(int64(1)))
+	z[d.N] = big.NewInt(int64(1))
+	return z
+}
+
+// isVanishingPolynomial returns the size of the Domain if z is of the form x^N - 1, and 0 otherwise
+func (pf PolynomialField) isVanishingPolynomial(z []*big.Int) int {
+	n := len(z) - 1
+	if n < 1 {
+		return 0
+	}
+	if !pf.F.Equal(z[0], pf.F.Neg(big.NewInt(int64(1)))) || !pf.F.Equal(z[n], big.NewInt(int64(1))) {
+		return 0
+	}
+	for i := 1; i < n; i++ {
+		if !pf.F.IsZero(pf.F.Affine(z[i])) {
+			return 0
+		}
+	}
+	return n
+}
+
+// divideByVanishing divides the polynomial p by x^n - 1 in O(len(p)), returning the quotient and the remainder
+func (pf PolynomialField) divideByVanishing(p []*big.Int, n int) ([]*big.Int, []*big.Int) {
+	if len(p) <= n {
+		return []*big.Int{}, p
+	}
+	// p(x) = q(x)(x^n - 1) + r(x), so p_i = q_(i-n) - q_i for i >= n
+	q := ArrayOfBigZeros(len(p) - n)
+	for i := len(p) - 1; i >= n; i-- {
+		qi := big.NewInt(int64(0))
+		if i < len(q) {
+			qi = q[i]
+		}
+		q[i-n] = pf.F.Add(p[i], qi)
+	}
+	// r_i = p_i + q_i for i < n
+	rem := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		rem[i] = pf.F.Affine(p[i])
+		if i < len(q) {
+			rem[i] = pf.F.Add(rem[i], q[i])
+		}
+	}
+	return q, rem
+}
+
+// MulNTT multiplies two polinomials over the Finite Field by evaluating them with the NTT, performing the pointwise multiplication and interpolating back the result
+func (pf PolynomialField) MulNTT(a, b []*big.Int) ([]*big.Int, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return []*big.Int{}, nil
+	}
+	size := len(a) + len(b) - 1
+	d, err := pf.NewDomain(size)
+	if err != nil {
+		return nil, err
+	}
+	aEval := pf.NTT(d, a)
+	bEval := pf.NTT(d, b)
+	for i := 0; i < d.N; i++ {
+		aEval[i] = pf.F.Mul(aEval[i], bEval[i])
+	}
+	return pf.INTT(d, aEval)[:size], nil
+}
+
+// evalR1CS returns the values of the linear combinations of the witness given by each row of the R1CS matrix m, which are the evaluations of the combined QAP polynomial over the Domain
+func (pf PolynomialField) evalR1CS(d Domain, m [][]*big.Int, w []*big.Int) []*big.Int {
+	evals := ArrayOfBigZeros(d.N)
+	for i := 0; i < len(m); i++ {
+		for j := 0; j < len(m[i]) && j < len(w); j++ {
+			if m[i][j].Sign() == 0 {
+				continue
+			}
+			evals[i] = pf.F.Add(evals[i], pf.F.Mul(m[i][j], w[j]))
+		}
+	}
+	return evals
+}
+
+// HFromWitness computes the polynomial H(x) = (A(x)*B(x) - C(x)) / Z(x) from the R1CS and the witness, without computing P(x). A, B and C are evaluated over the Domain, moved to a coset of it (where Z(x) has no zeros) and divided pointwise by Z(x). If c is nil, the witness is expected to satisfy the constraints and C(x) is obtained from A and B
+func (pf PolynomialField) HFromWitness(a, b, c [][]*big.Int, w []*big.Int) ([]*big.Int, error) {
+	return pf.HFromWitnessParallel(a, b, c, w, 1)
+}
+
+// HFromWitnessParallel computes the same than HFromWitness, but when workers > 1 the transforms of A, B and C are computed concurrently
+func (pf PolynomialField) HFromWitnessParallel(a, b, c [][]*big.Int, w []*big.Int, workers int) ([]*big.Int, error) {
+	d, err := pf.NewDomain(len(a))
+	if err != nil {
+		return nil, err
+	}
+	// the coset is g·{1, ω, ..., ω^(N-1)}, being g the quadratic non residue, and Z(x) = g^N - 1 over all its points
+	g := d.CosetGen
+	gInv := pf.F.Inverse(g)
+	zCoset := pf.F.Sub(pf.F.Exp(g, big.NewInt(int64(d.N))), big.NewInt(int64(1)))
+	if pf.F.IsZero(zCoset) {
+		return nil, errors.New("the coset generator is a root of unity of the Domain")
+	}
+	zCosetInv := pf.F.Inverse(zCoset)
+
+	// evaluations over the coset: p(g·ω^i) is the NTT of the coefficients p_j·g^j
+	toCoset := func(evals []*big.Int) []*big.Int {
+		coefs := pf.INTT(d, evals)
+		gi := big.NewInt(int64(1))
+		for i := 0; i < d.N; i++ {
+			coefs[i] = pf.F.Mul(coefs[i], gi)
+			gi = pf.F.Mul(gi, g)
+		}
+		pf.ntt(coefs, d.Omega)
+		return coefs
+	}
+	evals := [3][]*big.Int{pf.evalR1CS(d, a, w), pf.evalR1CS(d, b, w), nil}
+	if c != nil {
+		evals[2] = pf.evalR1CS(d, c, w)
+	} else {
+		// without the C matrix, its evaluations are the products of the ones of A and B, as for any witness that satisfies the constraints
+		evals[2] = make([]*big.Int, d.N)
+		for i := 0; i < d.N; i++ {
+			evals[2][i] = pf.F.Mul(evals[0][i], evals[1][i])
+		}
+	}
+	var cosets [3][]*big.Int
+	if workers > 1 {
+		var wg sync.WaitGroup
+		wg.Add(len(evals))
+		for i := range evals {
+			go func(i int) {
+				defer wg.Done()
+				cosets[i] = toCoset(evals[i])
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range evals {
+			cosets[i] = toCoset(evals[i])
+		}
+	}
+	aCoset, bCoset, cCoset := cosets[0], cosets[1], cosets[2]
+
+	h := make([]*big.Int, d.N)
+	for i := 0; i < d.N; i++ {
+		h[i] = pf.F.Mul(pf.F.Sub(pf.F.Mul(aCoset[i], bCoset[i]), cCoset[i]), zCosetInv)
+	}
+
+	// back from the coset to the coefficients of H(x)
+	h = pf.INTT(d, h)
+	giInv := big.NewInt(int64(1))
+	for i := 0; i < d.N; i++ {
+		h[i] = pf.F.Mul(h[i], giInv)
+		giInv = pf.F.Mul(giInv, gInv)
+	}
+	// deg(H) = deg(A*B - C) - N <= N - 2
+	return h[:d.N-1], nil
+}
+
+// LagrangeBasisAt returns the evaluations at x of the Lagrange basis polynomials of the Domain, L_j(x) = (x^N - 1) / N * ω^j / (x - ω^j)
+func (pf PolynomialField) LagrangeBasisAt(d Domain, x *big.Int) []*big.Int {
+	l := ArrayOfBigZeros(d.N)
+	xN := pf.F.Exp(x, big.NewInt(int64(d.N)))
+	zx := pf.F.Sub(xN, big.NewInt(int64(1)))
+	if pf.F.IsZero(zx) {
+		// x is a point of the Domain
+		omegaj := big.NewInt(int64(1))
+		for j := 0; j < d.N; j++ {
+			if pf.F.Equal(x, omegaj) {
+				l[j] = big.NewInt(int64(1))
+			}
+			omegaj = pf.F.Mul(omegaj, d.Omega)
+		}
+		return l
+	}
+	zxNInv := pf.F.Mul(zx, d.NInv)
+	omegaj := big.NewInt(int64(1))
+	for j := 0; j < d.N; j++ {
+		l[j] = pf.F.Mul(zxNInv, pf.F.Div(omegaj, pf.F.Sub(x, omegaj)))
+		omegaj = pf.F.Mul(omegaj, d.Omega)
+	}
+	return l
+}
+
+// QAPEvalAt evaluates at x the QAP polynomials of the R1CS (which gives the same than evaluating the polynomials returned by R1CSToQAP) using the Lagrange basis of the Domain, so it only needs to go through the non zero elements of the R1CS matrices. The columns are split between the given number of goroutines
+func (pf PolynomialField) QAPEvalAt(a, b, c [][]*big.Int, x *big.Int, workers int) ([]*big.Int, []*big.Int, []*big.Int, error) {
+	if len(a) == 0 {
+		return nil, nil, nil, errors.New("empty R1CS")
+	}
+	d, err := pf.NewDomain(len(a))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	l := pf.LagrangeBasisAt(d, x)
+
+	nCols := len(a[0])
+	evalCols := func(m [][]*big.Int, r []*big.Int, from, to int) {
+		for i := from; i < to; i++ {
+			r[i] = big.NewInt(int64(0))
+		}
+		for j := 0; j < len(m); j++ {
+			for i := from; i < to && i < len(m[j]); i++ {
+				if m[j][i].Sign() == 0 {
+					continue
+				}
+				r[i] = pf.F.Add(r[i], pf.F.Mul(m[j][i], l[j]))
+			}
+		}
+	}
+	at := make([]*big.Int, nCols)
+	bt := make([]*big.Int, nCols)
+	ct := make([]*big.Int, nCols)
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		from, to := k*nCols/workers, (k+1)*nCols/workers
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			evalCols(a, at, from, to)
+			evalCols(b, bt, from, to)
+			evalCols(c, ct, from, to)
+		}(from, to)
+	}
+	wg.Wait()
+	return at, bt, ct, nil
+}
@@ -0,0 +1,147 @@
+package r1csqap
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/arnaucube/go-snark-study/fields"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPolynomialField(t *testing.T) PolynomialField {
+	r, ok := new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	assert.True(t, ok)
+	return NewPolynomialField(fields.NewFq(r))
+}
+
+func TestDomain(t *testing.T) {
+	pf := newTestPolynomialField(t)
+
+	d, err := pf.NewDomain(5)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, d.N)
+	// ω^N == 1 and ω^(N/2) == -1
+	assert.Equal(t, big.NewInt(int64(1)), pf.F.Exp(d.Omega, big.NewInt(int64(d.N))))
+	assert.True(t, pf.F.Equal(pf.F.Neg(big.NewInt(int64(1))), pf.F.Exp(d.Omega, big.NewInt(int64(d.N/2)))))
+	assert.True(t, pf.F.Equal(big.NewInt(int64(1)), pf.F.Mul(d.Omega, d.OmegaInv)))
+	// the coset generator is not a square: g^((q-1)/2) == -1
+	e := new(big.Int).Rsh(new(big.Int).Sub(pf.F.Q, big.NewInt(int64(1))), 1)
+	assert.True(t, pf.F.Equal(pf.F.Neg(big.NewInt(int64(1))), pf.F.Exp(d.CosetGen, e)))
+
+	// the BN128 R field has 2-adicity 28
+	_, err = pf.NewDomain(1 << 28)
+	assert.Nil(t, err)
+	_, err = pf.NewDomain(1<<28 + 1)
+	assert.NotNil(t, err)
+}
+
+func TestNTT(t *testing.T) {
+	pf := newTestPolynomialField(t)
+	d, err := pf.NewDomain(8)
+	assert.Nil(t, err)
+
+	var p []*big.Int
+	for i := 0; i < 6; i++ {
+		p = append(p, big.NewInt(int64(i*i+3)))
+	}
+	evals := pf.NTT(d, p)
+	x := big.NewInt(int64(1))
+	for i := 0; i < d.N; i++ {
+		assert.Equal(t, pf.Eval(p, x), evals[i])
+		x = pf.F.Mul(x, d.Omega)
+	}
+
+	coefs := pf.INTT(d, evals)
+	assert.True(t, BigArraysEqual(p, coefs[:len(p)]))
+	for i := len(p); i < d.N; i++ {
+		assert.True(t, pf.F.IsZero(coefs[i]))
+	}
+}
+
+func TestMulNTT(t *testing.T) {
+	pf := newTestPolynomialField(t)
+
+	var a, b []*big.Int
+	for i := 0; i < 100; i++ {
+		a = append(a, big.NewInt(int64(3*i+1)))
+	}
+	for i := 0; i < 70; i++ {
+		b = append(b, pf.F.Neg(big.NewInt(int64(i+5))))
+	}
+	m, err := pf.MulNTT(a, b)
+	assert.Nil(t, err)
+	assert.True(t, BigArraysEqual(pf.Mul(a, b), m))
+
+	// schoolbook multiplication
+	r := ArrayOfBigZeros(len(a) + len(b) - 1)
+	for i := 0; i < len(a); i++ {
+		for j := 0; j < len(b); j++ {
+			r[i+j] = pf.F.Add(r[i+j], pf.F.Mul(a[i], b[j]))
+		}
+	}
+	assert.True(t, BigArraysEqual(r, m))
+}
+
+func TestDivisorPolynomialVanishing(t *testing.T) {
+	pf := newTestPolynomialField(t)
+	d, err := pf.NewDomain(4)
+	assert.Nil(t, err)
+	z := pf.VanishingPolynomial(d)
+	for i := 0; i < d.N; i++ {
+		assert.True(t, pf.F.IsZero(pf.Eval(z, pf.F.Exp(d.Omega, big.NewInt(int64(i))))))
+	}
+
+	h := []*big.Int{big.NewInt(int64(7)), big.NewInt(int64(0)), big.NewInt(int64(2))}
+	p := pf.Mul(h, z)
+	assert.True(t, BigArraysEqual(h, pf.DivisorPolynomial(p, z)))
+
+	// same result than the long division
+	quo, rem := pf.Div(p, z)
+	assert.True(t, BigArraysEqual(quo, pf.DivisorPolynomial(p, z)))
+	_, fastRem := pf.divideByVanishing(p, d.N)
+	assert.True(t, BigArraysEqual(rem, fastRem))
+}
+
+func TestQAPEvalAt(t *testing.T) {
+	pf := newTestPolynomialField(t)
+
+	b0 := big.NewInt(int64(0))
+	b1 := big.NewInt(int64(1))
+	b5 := big.NewInt(int64(5))
+	a := [][]*big.Int{
+		[]*big.Int{b0, b1, b0, b0, b0, b0},
+		[]*big.Int{b0, b0, b0, b1, b0, b0},
+		[]*big.Int{b0, b1, b0, b0, b1, b0},
+		[]*big.Int{b5, b0, b0, b0, b0, b1},
+		[]*big.Int{b0, b0, b0, b0, b1, b0},
+	}
+	b := [][]*big.Int{
+		[]*big.Int{b0, b1, b0, b0, b0, b0},
+		[]*big.Int{b0, b1, b0, b0, b0, b0},
+		[]*big.Int{b1, b0, b0, b0, b0, b0},
+		[]*big.Int{b1, b0, b0, b0, b0, b0},
+		[]*big.Int{b1, b0, b0, b0, b0, b0},
+	}
+	c := [][]*big.Int{
+		[]*big.Int{b0, b0, b0, b1, b0, b0},
+		[]*big.Int{b0, b0, b0, b0, b1, b0},
+		[]*big.Int{b0, b0, b0, b0, b0, b1},
+		[]*big.Int{b0, b0, b1, b0, b0, b0},
+		[]*big.Int{b0, b0, b1, b0, b0, b0},
+	}
+	alphas, betas, gammas, _, err := pf.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
+	d, err := pf.NewDomain(len(a))
+	assert.Nil(t, err)
+
+	x := big.NewInt(int64(1234567))
+	// a point of the domain
+	omega3 := pf.F.Exp(d.Omega, big.NewInt(int64(3)))
+	for _, x := range []*big.Int{x, omega3} {
+		at, bt, ct, err := pf.QAPEvalAt(a, b, c, x, 4)
+		assert.Nil(t, err)
+		assert.True(t, BigArraysEqual(pf.BatchEval(alphas, x, 1), at))
+		assert.True(t, BigArraysEqual(pf.BatchEval(betas, x, 1), bt))
+		assert.True(t, BigArraysEqual(pf.BatchEval(gammas, x, 1), ct))
+	}
+}
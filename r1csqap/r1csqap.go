@@ -3,6 +3,7 @@ package r1csqap
 import (
 	"bytes"
 	"math/big"
+	"sync"
 
 	"github.com/arnaucube/go-snark-study/fields"
 )
@@ -55,6 +56,13 @@ func NewPolynomialField(f fields.Fq) PolynomialField {
 
 // Mul multiplies two polinomials over the Finite Field
 func (pf PolynomialField) Mul(a, b []*big.Int) []*big.Int {
+	if len(a) >= nttThreshold && len(b) >= nttThreshold {
+		r, err := pf.MulNTT(a, b)
+		if err == nil {
+			return r
+		}
+		// the Finite Field does not have a domain big enough, fallback to the schoolbook multiplication
+	}
 	r := ArrayOfBigZeros(len(a) + len(b) - 1)
 	for i := 0; i < len(a); i++ {
 		for j := 0; j < len(b); j++ {
@@ -116,12 +124,31 @@ func (pf PolynomialField) Sub(a, b []*big.Int) []*big.Int {
 
 // Eval evaluates the polinomial over the Finite Field at the given value x
 func (pf PolynomialField) Eval(v []*big.Int, x *big.Int) *big.Int {
+	// Horner's method: v0 + x*(v1 + x*(v2 + ...))
 	r := big.NewInt(int64(0))
-	for i := 0; i < len(v); i++ {
-		xi := pf.F.Exp(x, big.NewInt(int64(i)))
-		elem := pf.F.Mul(v[i], xi)
-		r = pf.F.Add(r, elem)
+	for i := len(v) - 1; i >= 0; i-- {
+		r = pf.F.Add(pf.F.Mul(r, x), v[i])
+	}
+	return r
+}
+
+// BatchEval evaluates all the polynomials at the given value x, splitting them between the given number of goroutines
+func (pf PolynomialField) BatchEval(pols [][]*big.Int, x *big.Int, workers int) []*big.Int {
+	r := make([]*big.Int, len(pols))
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			for i := k; i < len(pols); i += workers {
+				r[i] = pf.Eval(pols[i], x)
+			}
+		}(k)
 	}
+	wg.Wait()
 	return r
 }
 
@@ -157,34 +184,29 @@ func (pf PolynomialField) LagrangeInterpolation(v []*big.Int) []*big.Int {
 	return r
 }
 
-// R1CSToQAP converts the R1CS values to the QAP values
-func (pf PolynomialField) R1CSToQAP(a, b, c [][]*big.Int) ([][]*big.Int, [][]*big.Int, [][]*big.Int, []*big.Int) {
+// R1CSToQAP converts the R1CS values to the QAP values, interpolating each column over the Domain of roots of unity with the INTT. Also returns the vanishing polynomial Z(x) = x^n - 1 of the Domain, or an error if the Domain of the number of constraints can not be created
+func (pf PolynomialField) R1CSToQAP(a, b, c [][]*big.Int) ([][]*big.Int, [][]*big.Int, [][]*big.Int, []*big.Int, error) {
+	domain, err := pf.NewDomain(len(a))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
 	aT := Transpose(a)
 	bT := Transpose(b)
 	cT := Transpose(c)
 	var alphas [][]*big.Int
 	for i := 0; i < len(aT); i++ {
-		alphas = append(alphas, pf.LagrangeInterpolation(aT[i]))
+		alphas = append(alphas, pf.INTT(domain, aT[i]))
 	}
 	var betas [][]*big.Int
 	for i := 0; i < len(bT); i++ {
-		betas = append(betas, pf.LagrangeInterpolation(bT[i]))
+		betas = append(betas, pf.INTT(domain, bT[i]))
 	}
 	var gammas [][]*big.Int
 	for i := 0; i < len(cT); i++ {
-		gammas = append(gammas, pf.LagrangeInterpolation(cT[i]))
+		gammas = append(gammas, pf.INTT(domain, cT[i]))
 	}
-	z := []*big.Int{big.NewInt(int64(1))}
-	for i := 1; i < len(alphas)-1; i++ {
-		z = pf.Mul(
-			z,
-			[]*big.Int{
-				pf.F.Neg(
-					big.NewInt(int64(i))),
-				big.NewInt(int64(1)),
-			})
-	}
-	return alphas, betas, gammas, z
+	z := pf.VanishingPolynomial(domain)
+	return alphas, betas, gammas, z, nil
 }
 
 // CombinePolynomials combine the given polynomials arrays into one, also returns the P(x)
@@ -205,12 +227,20 @@ func (pf PolynomialField) CombinePolynomials(r []*big.Int, ap, bp, cp [][]*big.I
 		cx = pf.Add(cx, m)
 	}
 
-	px := pf.Sub(pf.Mul(ax, bx), cx)
+	abx, err := pf.MulNTT(ax, bx)
+	if err != nil {
+		abx = pf.Mul(ax, bx)
+	}
+	px := pf.Sub(abx, cx)
 	return ax, bx, cx, px
 }
 
-// DivisorPolynomial returns the divisor polynomial given two polynomials
+// DivisorPolynomial returns the divisor polynomial given two polynomials. When z is the vanishing polynomial of a Domain (x^n - 1) the division is done in linear time
 func (pf PolynomialField) DivisorPolynomial(px, z []*big.Int) []*big.Int {
+	if n := pf.isVanishingPolynomial(z); n > 0 {
+		quo, _ := pf.divideByVanishing(px, n)
+		return quo
+	}
 	quo, _ := pf.Div(px, z)
 	return quo
 }
@@ -92,6 +92,11 @@ func TestPol(t *testing.T) {
 	assert.Equal(t, pf.Eval(o, big.NewInt(3)), b4)
 	o = pf.NewPolZeroAt(2, 4, b3)
 	assert.Equal(t, pf.Eval(o, big.NewInt(2)), b3)
+
+	// polynomial evaluation
+	assert.Equal(t, big.NewInt(int64(21)), pf.Eval(a, b2)) // 1 + 5*2^2
+	evals := pf.BatchEval([][]*big.Int{a, b, c, {}}, b2, 3)
+	assert.Equal(t, []*big.Int{big.NewInt(int64(21)), big.NewInt(int64(7)), big.NewInt(int64(21)), b0}, evals)
 }
 
 func TestLagrangeInterpolation(t *testing.T) {
@@ -147,7 +152,8 @@ func TestR1CSToQAP(t *testing.T) {
 		[]*big.Int{b0, b0, b0, b0, b0, b1},
 		[]*big.Int{b0, b0, b1, b0, b0, b0},
 	}
-	alphas, betas, gammas, zx := pf.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, zx, err := pf.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	// fmt.Println(alphas)
 	// fmt.Println(betas)
 	// fmt.Println(gammas)
@@ -173,4 +179,16 @@ func TestR1CSToQAP(t *testing.T) {
 	hz := pf.Mul(hx, zx)
 	assert.Equal(t, abc, hz)
 
+	// h(x) from the witness, evaluating over the coset of the domain
+	hxCoset, err := pf.HFromWitness(a, b, c, w)
+	assert.Nil(t, err)
+	assert.Equal(t, hx, hxCoset)
+	// without the C matrix, as the witness satisfies the constraints
+	hxNoC, err := pf.HFromWitness(a, b, nil, w)
+	assert.Nil(t, err)
+	assert.True(t, BigArraysEqual(hx, hxNoC))
+
+	// the 2-adicity of F_7 is 1, so there is no Domain for the 4 points of a
+	_, _, _, _, err = NewPolynomialField(fields.NewFq(big.NewInt(int64(7)))).R1CSToQAP(a, b, c)
+	assert.NotNil(t, err)
 }
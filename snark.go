@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"runtime"
 
 	"github.com/arnaucube/go-snark-study/bn128"
 	"github.com/arnaucube/go-snark-study/circuitcompiler"
 	"github.com/arnaucube/go-snark-study/fields"
 	"github.com/arnaucube/go-snark-study/r1csqap"
+	"github.com/arnaucube/go-snark-study/utils/parallel"
 )
 
 type Pk struct { // Proving Key pk:=(pkA, pkB, pkC, pkH)
@@ -68,6 +70,44 @@ type Proof struct {
 	// PublicSignals []*big.Int
 }
 
+// ValidateProof returns an error if some point of the proof is not a valid point of its group
+func ValidateProof(proof Proof) error {
+	names := []string{"PiA", "PiAp", "PiBp", "PiC", "PiCp", "PiH", "PiKp"}
+	g1s := [][3]*big.Int{proof.PiA, proof.PiAp, proof.PiBp, proof.PiC, proof.PiCp, proof.PiH, proof.PiKp}
+	for i := 0; i < len(g1s); i++ {
+		if err := Utils.Bn.CheckG1(g1s[i]); err != nil {
+			return fmt.Errorf("invalid proof %s: %s", names[i], err)
+		}
+	}
+	if err := Utils.Bn.CheckG2(proof.PiB); err != nil {
+		return fmt.Errorf("invalid proof PiB: %s", err)
+	}
+	return nil
+}
+
+// ValidateVk returns an error if some point of the verification key is not a valid point of its group
+func ValidateVk(vk Vk) error {
+	for i := 0; i < len(vk.IC); i++ {
+		if err := Utils.Bn.CheckG1(vk.IC[i]); err != nil {
+			return fmt.Errorf("invalid vk IC[%d]: %s", i, err)
+		}
+	}
+	if err := Utils.Bn.CheckG1(vk.Vkb); err != nil {
+		return fmt.Errorf("invalid vk Vkb: %s", err)
+	}
+	if err := Utils.Bn.CheckG1(vk.G1Kbg); err != nil {
+		return fmt.Errorf("invalid vk G1Kbg: %s", err)
+	}
+	names := []string{"Vka", "Vkc", "G2Kbg", "G2Kg", "Vkz"}
+	g2s := [][3][2]*big.Int{vk.Vka, vk.Vkc, vk.G2Kbg, vk.G2Kg, vk.Vkz}
+	for i := 0; i < len(g2s); i++ {
+		if err := Utils.Bn.CheckG2(g2s[i]); err != nil {
+			return fmt.Errorf("invalid vk %s: %s", names[i], err)
+		}
+	}
+	return nil
+}
+
 type utils struct {
 	Bn  bn128.Bn128
 	FqR fields.Fq
@@ -177,58 +217,67 @@ func GenerateTrustedSetup(witnessLength int, circuit circuitcompiler.Circuit, al
 	setup.Vk.G2Kbg = Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, kbg)
 	setup.Vk.G2Kg = Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, setup.Toxic.Kgamma)
 
-	// for i := 0; i < circuit.NVars; i++ {
-	for i := 0; i < len(circuit.Signals); i++ {
-		at := Utils.PF.Eval(alphas[i], setup.Toxic.T)
-		// rhoAat := Utils.Bn.Fq1.Mul(setup.Toxic.RhoA, at)
-		rhoAat := Utils.FqR.Mul(setup.Toxic.RhoA, at)
-		a := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, rhoAat)
-		setup.Pk.A = append(setup.Pk.A, a)
-		if i <= circuit.NPublic {
-			setup.Vk.IC = append(setup.Vk.IC, a)
-		}
-
-		bt := Utils.PF.Eval(betas[i], setup.Toxic.T)
-		// rhoBbt := Utils.Bn.Fq1.Mul(setup.Toxic.RhoB, bt)
-		rhoBbt := Utils.FqR.Mul(setup.Toxic.RhoB, bt)
-		bg1 := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, rhoBbt)
-		bg2 := Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, rhoBbt)
-		setup.Pk.B = append(setup.Pk.B, bg2)
+	workers := runtime.NumCPU()
 
-		ct := Utils.PF.Eval(gammas[i], setup.Toxic.T)
-		// rhoCct := Utils.Bn.Fq1.Mul(setup.Toxic.RhoC, ct)
-		rhoCct := Utils.FqR.Mul(setup.Toxic.RhoC, ct)
-		c := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, rhoCct)
-		setup.Pk.C = append(setup.Pk.C, c)
+	// a(t), b(t), c(t) of all the signals, evaluated once. If the circuit has the R1CS they are evaluated with the Lagrange basis of the domain, which is much faster than evaluating the QAP polynomials
+	var at, bt, ct []*big.Int
+	if len(circuit.R1CS.A) > 0 {
+		at, bt, ct, err = Utils.PF.QAPEvalAt(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C, setup.Toxic.T, workers)
+		if err != nil {
+			return Setup{}, err
+		}
+	} else {
+		at = Utils.PF.BatchEval(alphas, setup.Toxic.T, workers)
+		bt = Utils.PF.BatchEval(betas, setup.Toxic.T, workers)
+		ct = Utils.PF.BatchEval(gammas, setup.Toxic.T, workers)
+	}
 
+	var rhoAats, rhoBbts, rhoCcts, kts []*big.Int
+	var kaRhoAats, kbRhoBbts, kcRhoCcts, kbetaKts []*big.Int
+	for i := 0; i < len(circuit.Signals); i++ {
+		rhoAat := Utils.FqR.Mul(setup.Toxic.RhoA, at[i])
+		rhoBbt := Utils.FqR.Mul(setup.Toxic.RhoB, bt[i])
+		rhoCct := Utils.FqR.Mul(setup.Toxic.RhoC, ct[i])
 		kt := Utils.FqR.Add(Utils.FqR.Add(rhoAat, rhoBbt), rhoCct)
-		k := Utils.Bn.G1.Affine(Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, kt))
+		rhoAats = append(rhoAats, rhoAat)
+		rhoBbts = append(rhoBbts, rhoBbt)
+		rhoCcts = append(rhoCcts, rhoCct)
+		kts = append(kts, kt)
+
+		// Ap = A * Ka, Bp = B * Kb, Cp = C * Kc, Kp = K * Kbeta
+		kaRhoAats = append(kaRhoAats, Utils.FqR.Mul(rhoAat, setup.Toxic.Ka))
+		kbRhoBbts = append(kbRhoBbts, Utils.FqR.Mul(rhoBbt, setup.Toxic.Kb))
+		kcRhoCcts = append(kcRhoCcts, Utils.FqR.Mul(rhoCct, setup.Toxic.Kc))
+		kbetaKts = append(kbetaKts, Utils.FqR.Mul(kt, setup.Toxic.Kbeta))
+	}
+
+	setup.Pk.A = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, rhoAats, workers)
+	setup.Vk.IC = append([][3]*big.Int{}, setup.Pk.A[:circuit.NPublic+1]...)
+	bg1s := Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, rhoBbts, workers)
+	setup.Pk.B = Utils.Bn.G2.BatchMulScalar(Utils.Bn.G2.G, rhoBbts, workers)
+	setup.Pk.C = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, rhoCcts, workers)
+	ks := Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, kts, workers)
 
-		ktest := Utils.Bn.G1.Affine(Utils.Bn.G1.Add(Utils.Bn.G1.Add(a, bg1), c))
+	for i := 0; i < len(circuit.Signals); i++ {
+		k := Utils.Bn.G1.Affine(ks[i])
+		ktest := Utils.Bn.G1.Affine(Utils.Bn.G1.Add(Utils.Bn.G1.Add(setup.Pk.A[i], bg1s[i]), setup.Pk.C[i]))
 		if !Utils.Bn.Fq2.Equal(k, ktest) {
 			os.Exit(1)
 			return setup, err
 		}
+	}
+
+	setup.Pk.Ap = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, kaRhoAats, workers)
+	setup.Pk.Bp = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, kbRhoBbts, workers)
+	setup.Pk.Cp = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, kcRhoCcts, workers)
+	setup.Pk.Kp = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, kbetaKts, workers)
 
-		setup.Pk.Ap = append(setup.Pk.Ap, Utils.Bn.G1.MulScalar(a, setup.Toxic.Ka))
-		setup.Pk.Bp = append(setup.Pk.Bp, Utils.Bn.G1.MulScalar(bg1, setup.Toxic.Kb))
-		setup.Pk.Cp = append(setup.Pk.Cp, Utils.Bn.G1.MulScalar(c, setup.Toxic.Kc))
-		k_ := Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, kt)
-		setup.Pk.Kp = append(setup.Pk.Kp, Utils.Bn.G1.MulScalar(k_, setup.Toxic.Kbeta))
-	}
-
-	// z pol
-	zpol := []*big.Int{big.NewInt(int64(1))}
-	// for i := 0; i < len(circuit.Constraints); i++ {
-	for i := 1; i < len(alphas)-1; i++ {
-		zpol = Utils.PF.Mul(
-			zpol,
-			[]*big.Int{
-				Utils.FqR.Neg( // neg over R
-					big.NewInt(int64(i))),
-				big.NewInt(int64(1)),
-			})
+	// z pol: x^n - 1, vanishing over the domain where the QAP polynomials have been interpolated
+	domain, err := Utils.PF.NewDomain(len(alphas[0]))
+	if err != nil {
+		return Setup{}, err
 	}
+	zpol := Utils.PF.VanishingPolynomial(domain)
 	setup.Pk.Z = zpol
 
 	zt := Utils.PF.Eval(zpol, setup.Toxic.T)
@@ -237,52 +286,77 @@ func GenerateTrustedSetup(witnessLength int, circuit circuitcompiler.Circuit, al
 	setup.Vk.Vkz = Utils.Bn.G2.MulScalar(Utils.Bn.G2.G, rhoCzt)
 
 	// encrypt t values with curve generators
-	var gt1 [][3]*big.Int
-	gt1 = append(gt1, Utils.Bn.G1.G) // the first is t**0 * G1 = 1 * G1 = G1
-	tEncr := setup.Toxic.T
-	for i := 1; i < len(zpol); i++ { //should be G1T = pkH = (tau**i * G1) from i=0 to d, where d is degree of pol Z(x)
-		gt1 = append(gt1, Utils.Bn.G1.MulScalar(Utils.Bn.G1.G, tEncr))
-		// tEncr = Utils.Bn.Fq1.Mul(tEncr, setup.Toxic.T)
-		tEncr = Utils.FqR.Mul(tEncr, setup.Toxic.T)
+	// G1T = pkH = (tau**i * G1) from i=0 to d, where d is degree of pol Z(x)
+	tPows := []*big.Int{big.NewInt(int64(1))}
+	for i := 1; i < len(zpol); i++ {
+		tPows = append(tPows, Utils.FqR.Mul(tPows[i-1], setup.Toxic.T))
 	}
-	setup.Pk.G1T = gt1
+	setup.Pk.G1T = Utils.Bn.G1.BatchMulScalar(Utils.Bn.G1.G, tPows, workers)
 
 	return setup, nil
 }
 
-// GenerateProofs generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness
-func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, px []*big.Int) (Proof, error) {
-	var proof Proof
-	proof.PiA = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiAp = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiB = Utils.Bn.Fq6.Zero()
-	proof.PiBp = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiC = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiCp = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiH = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
-	proof.PiKp = [3]*big.Int{Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero(), Utils.Bn.G1.F.Zero()}
+// ProverOptions are the options used by the prover
+type ProverOptions struct {
+	Workers int // number of goroutines used by the prover, if is 0 all the CPUs are used
+}
 
-	for i := circuit.NPublic + 1; i < circuit.NVars; i++ {
-		proof.PiA = Utils.Bn.G1.Add(proof.PiA, Utils.Bn.G1.MulScalar(pk.A[i], w[i]))
-		proof.PiAp = Utils.Bn.G1.Add(proof.PiAp, Utils.Bn.G1.MulScalar(pk.Ap[i], w[i]))
+// workers returns the number of goroutines to use
+func (opts ProverOptions) workers() int {
+	if opts.Workers <= 0 {
+		return runtime.NumCPU()
 	}
+	return opts.Workers
+}
 
-	for i := 0; i < circuit.NVars; i++ {
-		proof.PiB = Utils.Bn.G2.Add(proof.PiB, Utils.Bn.G2.MulScalar(pk.B[i], w[i]))
-		proof.PiBp = Utils.Bn.G1.Add(proof.PiBp, Utils.Bn.G1.MulScalar(pk.Bp[i], w[i]))
-
-		proof.PiC = Utils.Bn.G1.Add(proof.PiC, Utils.Bn.G1.MulScalar(pk.C[i], w[i]))
-		proof.PiCp = Utils.Bn.G1.Add(proof.PiCp, Utils.Bn.G1.MulScalar(pk.Cp[i], w[i]))
-
-		proof.PiKp = Utils.Bn.G1.Add(proof.PiKp, Utils.Bn.G1.MulScalar(pk.Kp[i], w[i]))
-	}
+// GenerateProofs generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness, using all the CPUs
+func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int) (Proof, error) {
+	return GenerateProofsWithOptions(circuit, pk, w, ProverOptions{})
+}
 
-	hx := Utils.PF.DivisorPolynomial(px, pk.Z) // maybe move this calculation to a previous step
+// GenerateProofsWithOptions generates all the parameters to proof the zkSNARK from the Circuit, Setup and the Witness, with the given ProverOptions. The Proof is the same for any number of workers
+func GenerateProofsWithOptions(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, opts ProverOptions) (Proof, error) {
+	var proof Proof
+	workers := opts.workers()
 
-	// piH = pkH,0 + sum (  hi * pk H,i ), where pkH = G1T, hi=hx
-	// proof.PiH = Utils.Bn.G1.Add(proof.PiH, pk.G1T[0])
-	for i := 0; i < len(hx); i++ {
-		proof.PiH = Utils.Bn.G1.Add(proof.PiH, Utils.Bn.G1.MulScalar(pk.G1T[i], hx[i]))
+	wPriv := w[circuit.NPublic+1 : circuit.NVars]
+	var err error
+	// the multi-scalar multiplications and h(x) are independent between them
+	parallel.RunTasks(workers,
+		func(workers int) {
+			proof.PiA = Utils.Bn.G1.MultiExpParallel(pk.A[circuit.NPublic+1:circuit.NVars], wPriv, workers)
+		},
+		func(workers int) {
+			proof.PiAp = Utils.Bn.G1.MultiExpParallel(pk.Ap[circuit.NPublic+1:circuit.NVars], wPriv, workers)
+		},
+		func(workers int) {
+			proof.PiB = Utils.Bn.G2.MultiExpParallel(pk.B[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiBp = Utils.Bn.G1.MultiExpParallel(pk.Bp[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiC = Utils.Bn.G1.MultiExpParallel(pk.C[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiCp = Utils.Bn.G1.MultiExpParallel(pk.Cp[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			proof.PiKp = Utils.Bn.G1.MultiExpParallel(pk.Kp[:circuit.NVars], w[:circuit.NVars], workers)
+		},
+		func(workers int) {
+			// h(x) = (a(x) * b(x) - c(x)) / z(x), computed from the R1CS and the witness over a coset of the domain
+			var hx []*big.Int
+			hx, err = Utils.PF.HFromWitnessParallel(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C, w, workers)
+			if err != nil {
+				return
+			}
+			// piH = pkH,0 + sum (  hi * pk H,i ), where pkH = G1T, hi=hx
+			proof.PiH = Utils.Bn.G1.MultiExpParallel(pk.G1T[:len(hx)], hx, workers)
+		},
+	)
+	if err != nil {
+		return Proof{}, err
 	}
 
 	return proof, nil
@@ -290,10 +364,17 @@ func GenerateProofs(circuit circuitcompiler.Circuit, pk Pk, w []*big.Int, px []*
 
 // VerifyProof verifies over the BN128 the Pairings of the Proof
 func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool {
+	if err := ValidateProof(proof); err != nil {
+		if debug {
+			fmt.Println("❌", err)
+		}
+		return false
+	}
+
 	// e(piA, Va) == e(piA', g2)
-	pairingPiaVa := Utils.Bn.Pairing(proof.PiA, vk.Vka)
-	pairingPiapG2 := Utils.Bn.Pairing(proof.PiAp, Utils.Bn.G2.G)
-	if !Utils.Bn.Fq12.Equal(pairingPiaVa, pairingPiapG2) {
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{proof.PiA, Utils.Bn.G1.Neg(proof.PiAp)},
+		[][3][2]*big.Int{vk.Vka, Utils.Bn.G2.G}) {
 		if debug {
 			fmt.Println("❌ e(piA, Va) == e(piA', g2), valid knowledge commitment for A")
 		}
@@ -304,9 +385,9 @@ func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool
 	}
 
 	// e(Vb, piB) == e(piB', g2)
-	pairingVbPib := Utils.Bn.Pairing(vk.Vkb, proof.PiB)
-	pairingPibpG2 := Utils.Bn.Pairing(proof.PiBp, Utils.Bn.G2.G)
-	if !Utils.Bn.Fq12.Equal(pairingVbPib, pairingPibpG2) {
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{vk.Vkb, Utils.Bn.G1.Neg(proof.PiBp)},
+		[][3][2]*big.Int{proof.PiB, Utils.Bn.G2.G}) {
 		if debug {
 			fmt.Println("❌ e(Vb, piB) == e(piB', g2), valid knowledge commitment for B")
 		}
@@ -317,9 +398,9 @@ func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool
 	}
 
 	// e(piC, Vc) == e(piC', g2)
-	pairingPicVc := Utils.Bn.Pairing(proof.PiC, vk.Vkc)
-	pairingPicpG2 := Utils.Bn.Pairing(proof.PiCp, Utils.Bn.G2.G)
-	if !Utils.Bn.Fq12.Equal(pairingPicVc, pairingPicpG2) {
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{proof.PiC, Utils.Bn.G1.Neg(proof.PiCp)},
+		[][3][2]*big.Int{vk.Vkc, Utils.Bn.G2.G}) {
 		if debug {
 			fmt.Println("❌ e(piC, Vc) == e(piC', g2), valid knowledge commitment for C")
 		}
@@ -335,12 +416,12 @@ func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool
 		vkxpia = Utils.Bn.G1.Add(vkxpia, Utils.Bn.G1.MulScalar(vk.IC[i+1], publicSignals[i]))
 	}
 
+	vkxpia = Utils.Bn.G1.Add(vkxpia, proof.PiA)
+
 	// e(Vkx+piA, piB) == e(piH, Vkz) * e(piC, g2)
-	if !Utils.Bn.Fq12.Equal(
-		Utils.Bn.Pairing(Utils.Bn.G1.Add(vkxpia, proof.PiA), proof.PiB), // TODO Add(vkxpia, proof.PiA) can go outside in order to save computation, as is reused later
-		Utils.Bn.Fq12.Mul(
-			Utils.Bn.Pairing(proof.PiH, vk.Vkz),
-			Utils.Bn.Pairing(proof.PiC, Utils.Bn.G2.G))) {
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{vkxpia, Utils.Bn.G1.Neg(proof.PiH), Utils.Bn.G1.Neg(proof.PiC)},
+		[][3][2]*big.Int{proof.PiB, vk.Vkz, Utils.Bn.G2.G}) {
 		if debug {
 			fmt.Println("❌ e(Vkx+piA, piB) == e(piH, Vkz) * e(piC, g2), QAP disibility checked")
 		}
@@ -352,12 +433,10 @@ func VerifyProof(vk Vk, proof Proof, publicSignals []*big.Int, debug bool) bool
 
 	// e(Vkx+piA+piC, g2KbetaKgamma) * e(g1KbetaKgamma, piB)
 	// == e(piK, g2Kgamma)
-	piApiC := Utils.Bn.G1.Add(Utils.Bn.G1.Add(vkxpia, proof.PiA), proof.PiC)
-	pairingPiACG2Kbg := Utils.Bn.Pairing(piApiC, vk.G2Kbg)
-	pairingG1KbgPiB := Utils.Bn.Pairing(vk.G1Kbg, proof.PiB)
-	pairingL := Utils.Bn.Fq12.Mul(pairingPiACG2Kbg, pairingG1KbgPiB)
-	pairingR := Utils.Bn.Pairing(proof.PiKp, vk.G2Kg)
-	if !Utils.Bn.Fq12.Equal(pairingL, pairingR) {
+	piApiC := Utils.Bn.G1.Add(vkxpia, proof.PiC)
+	if !Utils.Bn.PairingCheck(
+		[][3]*big.Int{piApiC, vk.G1Kbg, Utils.Bn.G1.Neg(proof.PiKp)},
+		[][3][2]*big.Int{vk.G2Kbg, proof.PiB, vk.G2Kg}) {
 		fmt.Println("❌ e(Vkx+piA+piC, g2KbetaKgamma) * e(g1KbetaKgamma, piB) == e(piK, g2Kgamma)")
 		return false
 	}
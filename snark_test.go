@@ -53,7 +53,8 @@ func TestGroth16MinimalFlow(t *testing.T) {
 
 	// R1CS to QAP
 	// TODO zxQAP is not used and is an old impl, TODO remove
-	alphas, betas, gammas, _ := Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, _, err := Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	fmt.Println("qap")
 	assert.Equal(t, 8, len(alphas))
 	assert.Equal(t, 8, len(alphas))
@@ -61,10 +62,10 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	assert.True(t, !bytes.Equal(alphas[1][1].Bytes(), big.NewInt(int64(0)).Bytes()))
 
 	ax, bx, cx, px := Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	assert.Equal(t, 7, len(ax))
-	assert.Equal(t, 7, len(bx))
-	assert.Equal(t, 7, len(cx))
-	assert.Equal(t, 13, len(px))
+	assert.Equal(t, 8, len(ax))
+	assert.Equal(t, 8, len(bx))
+	assert.Equal(t, 8, len(cx))
+	assert.Equal(t, 15, len(px))
 
 	// ---
 	// from here is the GROTH16
@@ -78,7 +79,7 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	hx := Utils.PF.DivisorPolynomial(px, setup.Pk.Z)
 	div, rem := Utils.PF.Div(px, setup.Pk.Z)
 	assert.Equal(t, hx, div)
-	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(6))
+	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(8))
 
 	// hx==px/zx so px==hx*zx
 	assert.Equal(t, px, Utils.PF.Mul(hx, setup.Pk.Z))
@@ -86,7 +87,7 @@ func TestGroth16MinimalFlow(t *testing.T) {
 	// check length of polynomials H(x) and Z(x)
 	assert.Equal(t, len(hx), len(px)-len(setup.Pk.Z)+1)
 
-	proof, err := groth16.GenerateProofs(*circuit, setup.Pk, w, px)
+	proof, err := groth16.GenerateProofs(*circuit, setup.Pk, w)
 	assert.Nil(t, err)
 
 	// fmt.Println("\n proofs:")
@@ -168,19 +169,20 @@ func TestZkFromFlatCircuitCode(t *testing.T) {
 
 	// R1CS to QAP
 	// TODO zxQAP is not used and is an old impl, TODO remove
-	alphas, betas, gammas, zxQAP := Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, zxQAP, err := Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	fmt.Println("qap")
 	assert.Equal(t, 8, len(alphas))
 	assert.Equal(t, 8, len(alphas))
 	assert.Equal(t, 8, len(alphas))
-	assert.Equal(t, 7, len(zxQAP))
+	assert.Equal(t, 9, len(zxQAP))
 	assert.True(t, !bytes.Equal(alphas[1][1].Bytes(), big.NewInt(int64(0)).Bytes()))
 
 	ax, bx, cx, px := Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	assert.Equal(t, 7, len(ax))
-	assert.Equal(t, 7, len(bx))
-	assert.Equal(t, 7, len(cx))
-	assert.Equal(t, 13, len(px))
+	assert.Equal(t, 8, len(ax))
+	assert.Equal(t, 8, len(bx))
+	assert.Equal(t, 8, len(cx))
+	assert.Equal(t, 15, len(px))
 
 	hxQAP := Utils.PF.DivisorPolynomial(px, zxQAP)
 	assert.Equal(t, 7, len(hxQAP))
@@ -196,7 +198,7 @@ func TestZkFromFlatCircuitCode(t *testing.T) {
 
 	div, rem := Utils.PF.Div(px, zxQAP)
 	assert.Equal(t, hxQAP, div)
-	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(6))
+	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(8))
 
 	// calculate trusted setup
 	setup, err := GenerateTrustedSetup(len(w), *circuit, alphas, betas, gammas)
@@ -211,7 +213,7 @@ func TestZkFromFlatCircuitCode(t *testing.T) {
 	// assert.Equal(t, hxQAP, hx)
 	div, rem = Utils.PF.Div(px, setup.Pk.Z)
 	assert.Equal(t, hx, div)
-	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(6))
+	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(8))
 
 	assert.Equal(t, px, Utils.PF.Mul(hxQAP, zxQAP))
 	// hx==px/zx so px==hx*zx
@@ -221,8 +223,21 @@ func TestZkFromFlatCircuitCode(t *testing.T) {
 	assert.Equal(t, len(hx), len(px)-len(setup.Pk.Z)+1)
 	assert.Equal(t, len(hxQAP), len(px)-len(zxQAP)+1)
 
-	proof, err := GenerateProofs(*circuit, setup.Pk, w, px)
+	// h(x) computed by the prover from the witness over the coset
+	hxCoset, err := Utils.PF.HFromWitness(a, b, c, w)
+	assert.Nil(t, err)
+	assert.Equal(t, hx, hxCoset)
+
+	proof, err := GenerateProofs(*circuit, setup.Pk, w)
+	assert.Nil(t, err)
+
+	// the parallel prover gives the same proof than the serial one
+	proofSerial, err := GenerateProofsWithOptions(*circuit, setup.Pk, w, ProverOptions{Workers: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, proofSerial, proof)
+	proofParallel, err := GenerateProofsWithOptions(*circuit, setup.Pk, w, ProverOptions{Workers: 4})
 	assert.Nil(t, err)
+	assert.Equal(t, proofSerial, proofParallel)
 
 	// fmt.Println("\n proofs:")
 	// fmt.Println(proof)
@@ -240,6 +255,14 @@ func TestZkFromFlatCircuitCode(t *testing.T) {
 	bOtherWrongPublic := big.NewInt(int64(34))
 	wrongPublicSignalsVerif := []*big.Int{bOtherWrongPublic}
 	assert.True(t, !VerifyProof(setup.Vk, proof, wrongPublicSignalsVerif, false))
+
+	// proofs with points out of the curve are rejected
+	assert.Nil(t, ValidateProof(proof))
+	assert.Nil(t, ValidateVk(setup.Vk))
+	invalidProof := proof
+	invalidProof.PiH = [3]*big.Int{proof.PiH[0], Utils.Bn.G1.F.Add(proof.PiH[1], big.NewInt(int64(1))), proof.PiH[2]}
+	assert.NotNil(t, ValidateProof(invalidProof))
+	assert.True(t, !VerifyProof(setup.Vk, invalidProof, publicSignalsVerif, false))
 }
 
 func TestZkMultiplication(t *testing.T) {
@@ -276,7 +299,8 @@ func TestZkMultiplication(t *testing.T) {
 
 	// R1CS to QAP
 	// TODO zxQAP is not used and is an old impl. TODO remove
-	alphas, betas, gammas, zxQAP := Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, zxQAP, err := Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	assert.Equal(t, 6, len(alphas))
 	assert.Equal(t, 6, len(betas))
 	assert.Equal(t, 6, len(betas))
@@ -329,7 +353,7 @@ func TestZkMultiplication(t *testing.T) {
 	assert.Equal(t, len(hx), len(px)-len(setup.Pk.Z)+1)
 	assert.Equal(t, len(hxQAP), len(px)-len(zxQAP)+1)
 
-	proof, err := GenerateProofs(*circuit, setup.Pk, w, px)
+	proof, err := GenerateProofs(*circuit, setup.Pk, w)
 	assert.Nil(t, err)
 
 	// fmt.Println("\n proofs:")
@@ -389,7 +413,8 @@ func TestMinimalFlow(t *testing.T) {
 
 	// R1CS to QAP
 	// TODO zxQAP is not used and is an old impl, TODO remove
-	alphas, betas, gammas, _ := Utils.PF.R1CSToQAP(a, b, c)
+	alphas, betas, gammas, _, err := Utils.PF.R1CSToQAP(a, b, c)
+	assert.Nil(t, err)
 	fmt.Println("qap")
 	assert.Equal(t, 8, len(alphas))
 	assert.Equal(t, 8, len(alphas))
@@ -397,10 +422,10 @@ func TestMinimalFlow(t *testing.T) {
 	assert.True(t, !bytes.Equal(alphas[1][1].Bytes(), big.NewInt(int64(0)).Bytes()))
 
 	ax, bx, cx, px := Utils.PF.CombinePolynomials(w, alphas, betas, gammas)
-	assert.Equal(t, 7, len(ax))
-	assert.Equal(t, 7, len(bx))
-	assert.Equal(t, 7, len(cx))
-	assert.Equal(t, 13, len(px))
+	assert.Equal(t, 8, len(ax))
+	assert.Equal(t, 8, len(bx))
+	assert.Equal(t, 8, len(cx))
+	assert.Equal(t, 15, len(px))
 
 	// calculate trusted setup
 	setup, err := GenerateTrustedSetup(len(w), *circuit, alphas, betas, gammas)
@@ -410,7 +435,7 @@ func TestMinimalFlow(t *testing.T) {
 	hx := Utils.PF.DivisorPolynomial(px, setup.Pk.Z)
 	div, rem := Utils.PF.Div(px, setup.Pk.Z)
 	assert.Equal(t, hx, div)
-	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(6))
+	assert.Equal(t, rem, r1csqap.ArrayOfBigZeros(8))
 
 	// hx==px/zx so px==hx*zx
 	assert.Equal(t, px, Utils.PF.Mul(hx, setup.Pk.Z))
@@ -418,7 +443,7 @@ func TestMinimalFlow(t *testing.T) {
 	// check length of polynomials H(x) and Z(x)
 	assert.Equal(t, len(hx), len(px)-len(setup.Pk.Z)+1)
 
-	proof, err := GenerateProofs(*circuit, setup.Pk, w, px)
+	proof, err := GenerateProofs(*circuit, setup.Pk, w)
 	assert.Nil(t, err)
 
 	// fmt.Println("\n proofs:")
@@ -111,6 +111,26 @@ func BigInt32ToString(b [3][2]*big.Int) [3][2]string {
 	return o
 }
 
+// [2][3][2]*big.Int
+func String232ToBigInt(s [2][3][2]string) ([2][3][2]*big.Int, error) {
+	var b [2][3][2]*big.Int
+	for i := 0; i < 2; i++ {
+		e, err := String32ToBigInt(s[i])
+		if err != nil {
+			return b, err
+		}
+		b[i] = e
+	}
+	return b, nil
+}
+
+func BigInt232ToString(b [2][3][2]*big.Int) [2][3][2]string {
+	var s [2][3][2]string
+	s[0] = BigInt32ToString(b[0])
+	s[1] = BigInt32ToString(b[1])
+	return s
+}
+
 // [][3][2]*big.Int
 func Array32StringToBigInt(s [][3][2]string) ([][3][2]*big.Int, error) {
 	var o [][3][2]*big.Int
@@ -251,6 +271,10 @@ func SetupFromString(s SetupString) (snark.Setup, error) {
 		return o, err
 	}
 
+	err = snark.ValidateVk(o.Vk)
+	if err != nil {
+		return o, err
+	}
 	return o, nil
 
 }
@@ -394,6 +418,10 @@ func ProofFromString(s ProofString) (snark.Proof, error) {
 	if err != nil {
 		return p, err
 	}
+	err = snark.ValidateProof(p)
+	if err != nil {
+		return p, err
+	}
 	return p, nil
 }
 
@@ -476,6 +504,10 @@ func GrothVkFromString(s GrothVkString) (groth16.Vk, error) {
 	if err != nil {
 		return vk, err
 	}
+	err = groth16.ValidateVk(vk)
+	if err != nil {
+		return vk, err
+	}
 	return vk, nil
 }
 func GrothSetupFromString(s GrothSetupString) (groth16.Setup, error) {
@@ -549,6 +581,10 @@ func GrothSetupFromString(s GrothSetupString) (groth16.Setup, error) {
 	if err != nil {
 		return o, err
 	}
+	err = groth16.ValidateVk(o.Vk)
+	if err != nil {
+		return o, err
+	}
 	return o, nil
 }
 
@@ -581,5 +617,9 @@ func GrothProofFromString(s GrothProofString) (groth16.Proof, error) {
 	if err != nil {
 		return p, err
 	}
+	err = groth16.ValidateProof(p)
+	if err != nil {
+		return p, err
+	}
 	return p, nil
 }
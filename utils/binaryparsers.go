@@ -0,0 +1,489 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	snark "github.com/arnaucube/go-snark-study"
+	"github.com/arnaucube/go-snark-study/bn128"
+	"github.com/arnaucube/go-snark-study/groth16"
+)
+
+// Binary key format. All the integers are little-endian uint32, the field elements (coordinates in Fq and scalars in Fr) are 32 bytes in little-endian, the G1 points are the affine x, y and the G2 points the affine x, y with the real part first. The zero point is encoded as x = y = 0. The arrays are prefixed by their length.
+// A file contains the KeyHeader, the Vk and then the Pk, so a verifier only needs to read the beginning of the file, and a prover can decode the Pk from a stream without holding the encoded file in memory. The Toxic values of the Setup are never stored.
+
+// KeyFormatVersion is the version of the binary key format
+const KeyFormatVersion = 1
+
+// protocols and curves of the KeyHeader
+const (
+	ProtocolPinocchio = 1
+	ProtocolGroth16   = 2
+	CurveBN128        = 1
+)
+
+var keyMagic = [4]byte{'g', 's', 'k', 'y'}
+
+const fqBinarySize = 32
+
+// KeyHeader is the header of a binary key file
+type KeyHeader struct {
+	Version    uint32
+	Protocol   uint32
+	Curve      uint32
+	NVars      uint32
+	NPublic    uint32
+	DomainSize uint32
+}
+
+// maxLen returns the maximum length of the arrays of the keys described by the header
+func (h KeyHeader) maxLen() uint32 {
+	if h.NVars > h.DomainSize {
+		return h.NVars + 1
+	}
+	return h.DomainSize + 1
+}
+
+// lenMinusOne returns n-1 for the NPublic and DomainSize of the header, or 0 if n is 0
+func lenMinusOne(n int) uint32 {
+	if n == 0 {
+		return 0
+	}
+	return uint32(n - 1)
+}
+
+// binWriter writes the values of the binary format, keeping the first error
+type binWriter struct {
+	w   io.Writer
+	bn  bn128.Bn128
+	buf [2 * 2 * fqBinarySize]byte
+	err error
+}
+
+func (bw *binWriter) write(b []byte) {
+	if bw.err != nil {
+		return
+	}
+	_, bw.err = bw.w.Write(b)
+}
+
+func (bw *binWriter) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(bw.buf[:4], v)
+	bw.write(bw.buf[:4])
+}
+
+// putFq writes the element in little-endian on the first fqBinarySize bytes of b
+func putFq(b []byte, a *big.Int) {
+	a.FillBytes(b[:fqBinarySize])
+	for i, j := 0, fqBinarySize-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+func (bw *binWriter) fq(a *big.Int) {
+	putFq(bw.buf[:], a)
+	bw.write(bw.buf[:fqBinarySize])
+}
+
+func (bw *binWriter) scalars(as []*big.Int) {
+	bw.uint32(uint32(len(as)))
+	for _, a := range as {
+		bw.fq(a)
+	}
+}
+
+func (bw *binWriter) g1(p [3]*big.Int) {
+	b := bw.buf[:2*fqBinarySize]
+	for i := range b {
+		b[i] = 0
+	}
+	// points that have not been set are stored as the zero point
+	if p[2] != nil && !bw.bn.G1.IsZero(p) {
+		a := bw.bn.G1.Affine(p)
+		putFq(b, a[0])
+		putFq(b[fqBinarySize:], a[1])
+	}
+	bw.write(b)
+}
+
+func (bw *binWriter) g1s(ps [][3]*big.Int) {
+	bw.uint32(uint32(len(ps)))
+	for _, p := range ps {
+		bw.g1(p)
+	}
+}
+
+func (bw *binWriter) g2(p [3][2]*big.Int) {
+	b := bw.buf[:]
+	for i := range b {
+		b[i] = 0
+	}
+	if p[2][0] != nil && !bw.bn.G2.IsZero(p) {
+		a := bw.bn.G2.Affine(p)
+		putFq(b, a[0][0])
+		putFq(b[fqBinarySize:], a[0][1])
+		putFq(b[2*fqBinarySize:], a[1][0])
+		putFq(b[3*fqBinarySize:], a[1][1])
+	}
+	bw.write(b)
+}
+
+func (bw *binWriter) g2s(ps [][3][2]*big.Int) {
+	bw.uint32(uint32(len(ps)))
+	for _, p := range ps {
+		bw.g2(p)
+	}
+}
+
+func (bw *binWriter) header(h KeyHeader) {
+	bw.write(keyMagic[:])
+	bw.uint32(h.Version)
+	bw.uint32(h.Protocol)
+	bw.uint32(h.Curve)
+	bw.uint32(h.NVars)
+	bw.uint32(h.NPublic)
+	bw.uint32(h.DomainSize)
+}
+
+// binReader reads the values of the binary format, keeping the first error
+type binReader struct {
+	r   io.Reader
+	bn  bn128.Bn128
+	max uint32 // maximum length of the arrays
+	buf [2 * 2 * fqBinarySize]byte
+	err error
+}
+
+func (br *binReader) read(n int) []byte {
+	if br.err != nil {
+		return nil
+	}
+	if _, err := io.ReadFull(br.r, br.buf[:n]); err != nil {
+		br.err = err
+		return nil
+	}
+	return br.buf[:n]
+}
+
+func (br *binReader) fail(msg string) {
+	if br.err == nil {
+		br.err = errors.New(msg)
+	}
+}
+
+func (br *binReader) uint32() uint32 {
+	b := br.read(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+// length reads the length of an array, checking that it is not bigger than the keys described by the header
+func (br *binReader) length() int {
+	l := br.uint32()
+	if l > br.max {
+		br.fail("array length not consistent with the key header")
+		return 0
+	}
+	return int(l)
+}
+
+// getLE reads a little-endian element from the first fqBinarySize bytes of b
+func getLE(b []byte) *big.Int {
+	var be [fqBinarySize]byte
+	for i := 0; i < fqBinarySize; i++ {
+		be[i] = b[fqBinarySize-1-i]
+	}
+	return new(big.Int).SetBytes(be[:])
+}
+
+// getFq reads an element of the base field from the first fqBinarySize bytes of b, checking that it is lower than Q
+func (br *binReader) getFq(b []byte) *big.Int {
+	a := getLE(b)
+	if a.Cmp(br.bn.Q) >= 0 {
+		br.fail("field element out of the field")
+	}
+	return a
+}
+
+// scalar reads an element of the scalar field, checking that it is lower than R
+func (br *binReader) scalar() *big.Int {
+	b := br.read(fqBinarySize)
+	if b == nil {
+		return nil
+	}
+	a := getLE(b)
+	if a.Cmp(br.bn.R) >= 0 {
+		br.fail("scalar out of the scalar field")
+	}
+	return a
+}
+
+func (br *binReader) scalars() []*big.Int {
+	n := br.length()
+	var as []*big.Int
+	for i := 0; i < n && br.err == nil; i++ {
+		as = append(as, br.scalar())
+	}
+	return as
+}
+
+func (br *binReader) g1() [3]*big.Int {
+	b := br.read(2 * fqBinarySize)
+	if b == nil {
+		return [3]*big.Int{}
+	}
+	x := br.getFq(b)
+	y := br.getFq(b[fqBinarySize:])
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return [3]*big.Int{br.bn.G1.F.Zero(), br.bn.G1.F.Zero(), br.bn.G1.F.Zero()}
+	}
+	p := [3]*big.Int{x, y, br.bn.G1.F.One()}
+	if !br.bn.G1.IsOnCurve(p) {
+		br.fail("G1 point not on the curve")
+	}
+	return p
+}
+
+func (br *binReader) g1s() [][3]*big.Int {
+	n := br.length()
+	var ps [][3]*big.Int
+	for i := 0; i < n && br.err == nil; i++ {
+		ps = append(ps, br.g1())
+	}
+	return ps
+}
+
+func (br *binReader) g2() [3][2]*big.Int {
+	b := br.read(4 * fqBinarySize)
+	if b == nil {
+		return [3][2]*big.Int{}
+	}
+	x := [2]*big.Int{br.getFq(b), br.getFq(b[fqBinarySize:])}
+	y := [2]*big.Int{br.getFq(b[2*fqBinarySize:]), br.getFq(b[3*fqBinarySize:])}
+	if br.bn.G2.F.IsZero(x) && br.bn.G2.F.IsZero(y) {
+		return br.bn.G2.Zero()
+	}
+	p := [3][2]*big.Int{x, y, br.bn.G2.F.One()}
+	if !br.bn.G2.IsOnCurve(p) {
+		br.fail("G2 point not on the curve")
+	}
+	return p
+}
+
+func (br *binReader) g2s() [][3][2]*big.Int {
+	n := br.length()
+	var ps [][3][2]*big.Int
+	for i := 0; i < n && br.err == nil; i++ {
+		ps = append(ps, br.g2())
+	}
+	return ps
+}
+
+// ReadKeyHeader reads and checks the header of a binary key file
+func ReadKeyHeader(r io.Reader) (KeyHeader, error) {
+	var h KeyHeader
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return h, err
+	}
+	if magic != keyMagic {
+		return h, errors.New("not a binary key file")
+	}
+	br := binReader{r: r}
+	h.Version = br.uint32()
+	h.Protocol = br.uint32()
+	h.Curve = br.uint32()
+	h.NVars = br.uint32()
+	h.NPublic = br.uint32()
+	h.DomainSize = br.uint32()
+	if br.err != nil {
+		return h, br.err
+	}
+	if h.Version != KeyFormatVersion {
+		return h, errors.New("unsupported binary key format version")
+	}
+	if h.Curve != CurveBN128 {
+		return h, errors.New("unsupported curve")
+	}
+	return h, nil
+}
+
+// newKeyReader reads the header of the key file and returns the binReader for the rest of the file
+func newKeyReader(r io.Reader, protocol uint32, bn bn128.Bn128) (*binReader, KeyHeader, error) {
+	h, err := ReadKeyHeader(r)
+	if err != nil {
+		return nil, h, err
+	}
+	if h.Protocol != protocol {
+		return nil, h, errors.New("binary key file of another protocol")
+	}
+	return &binReader{r: r, bn: bn, max: h.maxLen()}, h, nil
+}
+
+// WriteSetupBinary writes the Pk and Vk of the Setup in the binary key format
+func WriteSetupBinary(w io.Writer, setup snark.Setup) error {
+	bw := binWriter{w: w, bn: snark.Utils.Bn}
+	bw.header(KeyHeader{
+		Version:    KeyFormatVersion,
+		Protocol:   ProtocolPinocchio,
+		Curve:      CurveBN128,
+		NVars:      uint32(len(setup.Pk.A)),
+		NPublic:    lenMinusOne(len(setup.Vk.IC)),
+		DomainSize: lenMinusOne(len(setup.Pk.Z)),
+	})
+
+	bw.g2(setup.Vk.Vka)
+	bw.g1(setup.Vk.Vkb)
+	bw.g2(setup.Vk.Vkc)
+	bw.g1s(setup.Vk.IC)
+	bw.g1(setup.Vk.G1Kbg)
+	bw.g2(setup.Vk.G2Kbg)
+	bw.g2(setup.Vk.G2Kg)
+	bw.g2(setup.Vk.Vkz)
+
+	bw.g1s(setup.Pk.G1T)
+	bw.g1s(setup.Pk.A)
+	bw.g2s(setup.Pk.B)
+	bw.g1s(setup.Pk.C)
+	bw.g1s(setup.Pk.Kp)
+	bw.g1s(setup.Pk.Ap)
+	bw.g1s(setup.Pk.Bp)
+	bw.g1s(setup.Pk.Cp)
+	bw.scalars(setup.Pk.Z)
+	return bw.err
+}
+
+func readVk(br *binReader) snark.Vk {
+	var vk snark.Vk
+	vk.Vka = br.g2()
+	vk.Vkb = br.g1()
+	vk.Vkc = br.g2()
+	vk.IC = br.g1s()
+	vk.G1Kbg = br.g1()
+	vk.G2Kbg = br.g2()
+	vk.G2Kg = br.g2()
+	vk.Vkz = br.g2()
+	return vk
+}
+
+// ReadVkBinary reads the Vk from a binary key file, without reading the Pk
+func ReadVkBinary(r io.Reader) (snark.Vk, error) {
+	br, _, err := newKeyReader(r, ProtocolPinocchio, snark.Utils.Bn)
+	if err != nil {
+		return snark.Vk{}, err
+	}
+	vk := readVk(br)
+	if br.err != nil {
+		return vk, br.err
+	}
+	return vk, snark.ValidateVk(vk)
+}
+
+// ReadSetupBinary reads the Pk and Vk from a binary key file. The file is decoded while it is read, so r can be a stream
+func ReadSetupBinary(r io.Reader) (snark.Setup, error) {
+	var setup snark.Setup
+	br, _, err := newKeyReader(r, ProtocolPinocchio, snark.Utils.Bn)
+	if err != nil {
+		return setup, err
+	}
+	setup.Vk = readVk(br)
+	setup.Pk.G1T = br.g1s()
+	setup.Pk.A = br.g1s()
+	setup.Pk.B = br.g2s()
+	setup.Pk.C = br.g1s()
+	setup.Pk.Kp = br.g1s()
+	setup.Pk.Ap = br.g1s()
+	setup.Pk.Bp = br.g1s()
+	setup.Pk.Cp = br.g1s()
+	setup.Pk.Z = br.scalars()
+	if br.err != nil {
+		return setup, br.err
+	}
+	return setup, snark.ValidateVk(setup.Vk)
+}
+
+// WriteGrothSetupBinary writes the Pk and Vk of the Setup in the binary key format
+func WriteGrothSetupBinary(w io.Writer, setup groth16.Setup) error {
+	bw := binWriter{w: w, bn: groth16.Utils.Bn}
+	bw.header(KeyHeader{
+		Version:    KeyFormatVersion,
+		Protocol:   ProtocolGroth16,
+		Curve:      CurveBN128,
+		NVars:      uint32(len(setup.Pk.G1.At)),
+		NPublic:    lenMinusOne(len(setup.Vk.IC)),
+		DomainSize: lenMinusOne(len(setup.Pk.Z)),
+	})
+
+	bw.g1s(setup.Vk.IC)
+	bw.g1(setup.Vk.G1.Alpha)
+	bw.g2(setup.Vk.G2.Beta)
+	bw.g2(setup.Vk.G2.Gamma)
+	bw.g2(setup.Vk.G2.Delta)
+
+	bw.g1s(setup.Pk.BACDelta)
+	bw.scalars(setup.Pk.Z)
+	bw.g1(setup.Pk.G1.Alpha)
+	bw.g1(setup.Pk.G1.Beta)
+	bw.g1(setup.Pk.G1.Delta)
+	bw.g1s(setup.Pk.G1.At)
+	bw.g1s(setup.Pk.G1.BACGamma)
+	bw.g2(setup.Pk.G2.Beta)
+	bw.g2(setup.Pk.G2.Gamma)
+	bw.g2(setup.Pk.G2.Delta)
+	bw.g2s(setup.Pk.G2.BACGamma)
+	bw.g1s(setup.Pk.PowersTauDelta)
+	return bw.err
+}
+
+func readGrothVk(br *binReader) groth16.Vk {
+	var vk groth16.Vk
+	vk.IC = br.g1s()
+	vk.G1.Alpha = br.g1()
+	vk.G2.Beta = br.g2()
+	vk.G2.Gamma = br.g2()
+	vk.G2.Delta = br.g2()
+	return vk
+}
+
+// ReadGrothVkBinary reads the Vk from a binary key file, without reading the Pk
+func ReadGrothVkBinary(r io.Reader) (groth16.Vk, error) {
+	br, _, err := newKeyReader(r, ProtocolGroth16, groth16.Utils.Bn)
+	if err != nil {
+		return groth16.Vk{}, err
+	}
+	vk := readGrothVk(br)
+	if br.err != nil {
+		return vk, br.err
+	}
+	return vk, groth16.ValidateVk(vk)
+}
+
+// ReadGrothSetupBinary reads the Pk and Vk from a binary key file. The file is decoded while it is read, so r can be a stream
+func ReadGrothSetupBinary(r io.Reader) (groth16.Setup, error) {
+	var setup groth16.Setup
+	br, _, err := newKeyReader(r, ProtocolGroth16, groth16.Utils.Bn)
+	if err != nil {
+		return setup, err
+	}
+	setup.Vk = readGrothVk(br)
+	setup.Pk.BACDelta = br.g1s()
+	setup.Pk.Z = br.scalars()
+	setup.Pk.G1.Alpha = br.g1()
+	setup.Pk.G1.Beta = br.g1()
+	setup.Pk.G1.Delta = br.g1()
+	setup.Pk.G1.At = br.g1s()
+	setup.Pk.G1.BACGamma = br.g1s()
+	setup.Pk.G2.Beta = br.g2()
+	setup.Pk.G2.Gamma = br.g2()
+	setup.Pk.G2.Delta = br.g2()
+	setup.Pk.G2.BACGamma = br.g2s()
+	setup.Pk.PowersTauDelta = br.g1s()
+	if br.err != nil {
+		return setup, br.err
+	}
+	return setup, groth16.ValidateVk(setup.Vk)
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	snark "github.com/arnaucube/go-snark-study"
+	"github.com/arnaucube/go-snark-study/circuitcompiler"
+	"github.com/arnaucube/go-snark-study/groth16"
+	"github.com/arnaucube/go-snark-study/r1csqap"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCircuit(t *testing.T) (*circuitcompiler.Circuit, []*big.Int) {
+	code := `
+	func main(private s0, public s1):
+		s2 = s0 * s0
+		s3 = s2 * s0
+		s4 = s3 + s0
+		s5 = s4 + 5
+		equals(s1, s5)
+		out = 1 * 1
+	`
+	parser := circuitcompiler.NewParser(strings.NewReader(code))
+	circuit, err := parser.Parse()
+	assert.Nil(t, err)
+	w, err := circuit.CalculateWitness([]*big.Int{big.NewInt(int64(3))}, []*big.Int{big.NewInt(int64(35))})
+	assert.Nil(t, err)
+	circuit.GenerateR1CS()
+	return circuit, w
+}
+
+func TestGrothSetupBinary(t *testing.T) {
+	circuit, w := testCircuit(t)
+	alphas, betas, gammas, _, err := groth16.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	assert.Nil(t, err)
+	setup, err := groth16.GenerateTrustedSetup(len(w), *circuit, alphas, betas, gammas)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteGrothSetupBinary(&buf, setup))
+	encoded := buf.Bytes()
+
+	h, err := ReadKeyHeader(bytes.NewReader(encoded))
+	assert.Nil(t, err)
+	assert.Equal(t, KeyHeader{KeyFormatVersion, ProtocolGroth16, CurveBN128, uint32(circuit.NVars), uint32(circuit.NPublic), 8}, h)
+
+	decoded, err := ReadGrothSetupBinary(bytes.NewReader(encoded))
+	assert.Nil(t, err)
+	vk, err := ReadGrothVkBinary(bytes.NewReader(encoded))
+	assert.Nil(t, err)
+	assert.Equal(t, len(setup.Pk.G1.At), len(decoded.Pk.G1.At))
+	for i := range setup.Pk.G1.At {
+		assert.True(t, groth16.Utils.Bn.G1.Equal(setup.Pk.G1.At[i], decoded.Pk.G1.At[i]))
+	}
+	for i := range setup.Pk.G2.BACGamma {
+		assert.True(t, groth16.Utils.Bn.G2.Equal(setup.Pk.G2.BACGamma[i], decoded.Pk.G2.BACGamma[i]))
+	}
+	assert.True(t, r1csqap.BigArraysEqual(setup.Pk.Z, decoded.Pk.Z))
+
+	// the decoded keys produce and verify proofs
+	proof, err := groth16.GenerateProofs(*circuit, decoded.Pk, w)
+	assert.Nil(t, err)
+	assert.True(t, groth16.VerifyProof(vk, proof, []*big.Int{big.NewInt(int64(35))}, false))
+	assert.True(t, groth16.VerifyProof(setup.Vk, proof, []*big.Int{big.NewInt(int64(35))}, false))
+
+	// the Vk of other protocol is rejected
+	_, err = ReadVkBinary(bytes.NewReader(encoded))
+	assert.NotNil(t, err)
+	// truncated file
+	_, err = ReadGrothSetupBinary(bytes.NewReader(encoded[:len(encoded)-10]))
+	assert.NotNil(t, err)
+	// point not on the curve, the first coordinate of Vk.IC[0]
+	corrupted := append([]byte{}, encoded...)
+	corrupted[4+6*4+4]++
+	_, err = ReadGrothVkBinary(bytes.NewReader(corrupted))
+	assert.NotNil(t, err)
+	// the coefficients of Z are scalars, lower than R
+	outOfR := setup
+	outOfR.Pk.Z = append([]*big.Int{groth16.Utils.Bn.R}, setup.Pk.Z[1:]...)
+	buf.Reset()
+	assert.Nil(t, WriteGrothSetupBinary(&buf, outOfR))
+	_, err = ReadGrothSetupBinary(bytes.NewReader(buf.Bytes()))
+	assert.EqualError(t, err, "scalar out of the scalar field")
+}
+
+func TestSetupBinary(t *testing.T) {
+	circuit, w := testCircuit(t)
+	alphas, betas, gammas, _, err := snark.Utils.PF.R1CSToQAP(circuit.R1CS.A, circuit.R1CS.B, circuit.R1CS.C)
+	assert.Nil(t, err)
+	setup, err := snark.GenerateTrustedSetup(len(w), *circuit, alphas, betas, gammas)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteSetupBinary(&buf, setup))
+	decoded, err := ReadSetupBinary(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	vk, err := ReadVkBinary(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+
+	proof, err := snark.GenerateProofs(*circuit, decoded.Pk, w)
+	assert.Nil(t, err)
+	assert.True(t, snark.VerifyProof(vk, proof, []*big.Int{big.NewInt(int64(35))}, false))
+}
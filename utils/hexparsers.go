@@ -253,6 +253,10 @@ func SetupFromHex(s SetupHex) (snark.Setup, error) {
 		return o, err
 	}
 
+	err = snark.ValidateVk(o.Vk)
+	if err != nil {
+		return o, err
+	}
 	return o, nil
 
 }
@@ -396,6 +400,10 @@ func ProofFromHex(s ProofHex) (snark.Proof, error) {
 	if err != nil {
 		return p, err
 	}
+	err = snark.ValidateProof(p)
+	if err != nil {
+		return p, err
+	}
 	return p, nil
 }
 
@@ -526,6 +534,10 @@ func GrothSetupFromHex(s GrothSetupHex) (groth16.Setup, error) {
 	if err != nil {
 		return o, err
 	}
+	err = groth16.ValidateVk(o.Vk)
+	if err != nil {
+		return o, err
+	}
 	return o, nil
 }
 
@@ -558,5 +570,9 @@ func GrothProofFromHex(s GrothProofHex) (groth16.Proof, error) {
 	if err != nil {
 		return p, err
 	}
+	err = groth16.ValidateProof(p)
+	if err != nil {
+		return p, err
+	}
 	return p, nil
 }
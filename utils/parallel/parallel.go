@@ -0,0 +1,38 @@
+package parallel
+
+import "sync"
+
+// RunTasks executes the tasks on a pool of at most workers goroutines. Each task receives the number of goroutines that it can use, so all the tasks together do not use more than workers goroutines. With workers <= 1 the tasks are executed one after the other
+func RunTasks(workers int, tasks ...func(workers int)) {
+	if workers <= 1 || len(tasks) <= 1 {
+		if workers < 1 {
+			workers = 1
+		}
+		for _, task := range tasks {
+			task(workers)
+		}
+		return
+	}
+	pool := workers
+	if len(tasks) < pool {
+		pool = len(tasks)
+	}
+	taskWorkers := workers / pool
+
+	queue := make(chan func(workers int))
+	var wg sync.WaitGroup
+	wg.Add(pool)
+	for i := 0; i < pool; i++ {
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				task(taskWorkers)
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	wg.Wait()
+}
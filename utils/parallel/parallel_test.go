@@ -0,0 +1,42 @@
+package parallel
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunTasks(t *testing.T) {
+	for _, workers := range []int{0, 1, 2, 3, 8, 20} {
+		var mutex sync.Mutex
+		running, maxRunning := 0, 0
+		done := make([]bool, 7)
+		var tasks []func(workers int)
+		for i := range done {
+			i := i
+			tasks = append(tasks, func(taskWorkers int) {
+				mutex.Lock()
+				running += taskWorkers
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mutex.Unlock()
+				done[i] = true
+				mutex.Lock()
+				running -= taskWorkers
+				mutex.Unlock()
+			})
+		}
+		RunTasks(workers, tasks...)
+		for i := range done {
+			assert.True(t, done[i], workers)
+		}
+		// the goroutines of the tasks running at the same time are bounded by workers
+		if workers > 1 {
+			assert.True(t, maxRunning <= workers, workers)
+		} else {
+			assert.Equal(t, 1, maxRunning)
+		}
+	}
+}
@@ -17,14 +17,15 @@ set cpo&vim
 
 syn keyword goSnarkCircuitCommentTodo      TODO FIXME XXX TBD contained
 syn match   goSnarkCircuitLineComment      "\/\/.*" contains=@Spell,goSnarkCircuitCommentTodo
+syn region  goSnarkCircuitBlockComment     start="/\*" end="\*/" contains=@Spell,goSnarkCircuitCommentTodo
 syn match   goSnarkCircuitSpecialCharacter "'\\.'"
 syn match   goSnarkCircuitNumber	       "-\=\<\d\+L\=\>\|0[xX][0-9a-fA-F]\+\>"
-syn match goSnarkCircuitOpSymbols "+\|-\|\*\|:\|)\|(\|="
+syn match goSnarkCircuitOpSymbols "+\|-\|\*\|\^\|:\|)\|(\|=\|,\|<\|>\|!=\|?"
 syn keyword goSnarkCircuitPrivatePublic		private public
 syn keyword goSnarkCircuitOut	out
-syn keyword goSnarkCircuitEquals	equals
+syn keyword goSnarkCircuitEquals	equals assert_bool num2bits
 syn keyword goSnarkCircuitFunction	func
-syn keyword goSnarkCircuitStatement	return
+syn keyword goSnarkCircuitStatement	return if else for in
 syn keyword goSnarkCircuitImport	import
 syn match goSnarkCircuitFuncCall /\<\K\k*\ze\s*(/
 syn keyword goSnarkCircuitPrivate private nextgroup=goSnarkCircuitInputName skipwhite
@@ -40,6 +41,7 @@ syn sync maxlines=100
 " Define the default highlighting.
 " Only when an item doesn't have highlighting yet
 hi def link goSnarkCircuitLineComment		Comment
+hi def link goSnarkCircuitBlockComment		Comment
 hi def link goSnarkCircuitCommentTodo		Todo
 hi def link goSnarkCircuitSpecialCharacter	Special
 hi def link goSnarkCircuitNumber		Number
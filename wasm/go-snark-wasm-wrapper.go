@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/json"
-	"math/big"
 	"syscall/js"
 
 	"github.com/arnaucube/go-snark-study"
@@ -58,29 +57,14 @@ func generateProofs(this js.Value, i []js.Value) interface{} {
 	}
 	println("set", string(sj))
 
-	var pxStr []string
-	err = json.Unmarshal([]byte(i[2].String()), &pxStr)
-	if err != nil {
-		println("error parsing pxStr from stringified json")
-	}
-	px, err := utils.ArrayStringToBigInt(pxStr)
-	if err != nil {
-		println(err.Error())
-	}
-	sj, err = json.Marshal(px)
-	if err != nil {
-		println("error " + err.Error())
-	}
-	println("px", string(sj))
-
 	var inputs circuitcompiler.Inputs
-	err = json.Unmarshal([]byte(i[3].String()), &inputs)
+	err = json.Unmarshal([]byte(i[2].String()), &inputs)
 	if err != nil {
 		println("error parsing inputs from stringified json")
 	}
 	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
 
-	proof, err := snark.GenerateProofs(circuit, setup.Pk, w, px)
+	proof, err := snark.GenerateProofs(circuit, setup.Pk, w)
 	if err != nil {
 		println("error generating proof", err)
 	}
@@ -116,7 +100,7 @@ func verifyProofs(this js.Value, i []js.Value) interface{} {
 		println("error " + err.Error())
 	}
 
-	var publicInputs []*big.Int
+	var publicInputs circuitcompiler.InputValues
 	err = json.Unmarshal([]byte(i[2].String()), &publicInputs)
 	if err != nil {
 		println(i[2].String())
@@ -168,29 +152,14 @@ func grothGenerateProofs(this js.Value, i []js.Value) interface{} {
 	}
 	println("set", string(sj))
 
-	var pxStr []string
-	err = json.Unmarshal([]byte(i[2].String()), &pxStr)
-	if err != nil {
-		println("error parsing pxStr from stringified json")
-	}
-	px, err := utils.ArrayStringToBigInt(pxStr)
-	if err != nil {
-		println(err.Error())
-	}
-	sj, err = json.Marshal(px)
-	if err != nil {
-		println("error " + err.Error())
-	}
-	println("px", string(sj))
-
 	var inputs circuitcompiler.Inputs
-	err = json.Unmarshal([]byte(i[3].String()), &inputs)
+	err = json.Unmarshal([]byte(i[2].String()), &inputs)
 	if err != nil {
 		println("error parsing inputs from stringified json")
 	}
 	w, err := circuit.CalculateWitness(inputs.Private, inputs.Public)
 
-	proof, err := groth16.GenerateProofs(circuit, setup.Pk, w, px)
+	proof, err := groth16.GenerateProofs(circuit, setup.Pk, w)
 	if err != nil {
 		println("error generating proof", err)
 	}
@@ -226,7 +195,7 @@ func grothVerifyProofs(this js.Value, i []js.Value) interface{} {
 		println("error " + err.Error())
 	}
 
-	var publicInputs []*big.Int
+	var publicInputs circuitcompiler.InputValues
 	err = json.Unmarshal([]byte(i[2].String()), &publicInputs)
 	if err != nil {
 		println(i[2].String())